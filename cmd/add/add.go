@@ -1,15 +1,16 @@
 package add
 
 import (
-	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/candrewlee14/webman/cmd/complete"
 	"github.com/candrewlee14/webman/config"
 	"github.com/candrewlee14/webman/multiline"
 	"github.com/candrewlee14/webman/ui"
@@ -20,10 +21,7 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var (
-	doRefresh  bool
-	switchFlag bool
-)
+var switchFlag bool
 
 // addCmd represents the add command
 var AddCmd = &cobra.Command{
@@ -35,6 +33,7 @@ The "add" subcommand installs packages.`,
 webman add go@18.0.0
 webman add go zig rg
 webman add go@18.0.0 zig@9.1.0 rg@13.0.0`,
+	ValidArgsFunction: complete.Packages,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 {
 			return cmd.Help()
@@ -44,39 +43,81 @@ webman add go@18.0.0 zig@9.1.0 rg@13.0.0`,
 			return err
 		}
 		defer os.RemoveAll(utils.WebmanTmpDir)
-		// if local recipe flag is not set
-		if utils.RecipeDirFlag == "" {
-			// only refresh if not using local
-			for _, pkgRepo := range cfg.PkgRepos {
-				shouldRefresh, err := pkgRepo.ShouldRefreshRecipes(cfg.RefreshInterval)
-				if err != nil {
-					return err
-				}
-				if shouldRefresh || doRefresh {
-					color.HiBlue("Refreshing package recipes for %q...", pkgRepo.Name)
-					if err = pkgRepo.RefreshRecipes(); err != nil {
-						color.Red("%v", err)
-					}
-				}
-			}
+		if err := cfg.RefreshDueRepos(); err != nil {
+			return err
 		}
-		pkgs := InstallAllPkgs(cfg.PkgRepos, args, false, switchFlag)
-		for _, pkg := range pkgs {
-			fmt.Print(pkg.PkgConf.InstallNotes())
+		pkgs, errs := InstallAllPkgs(cfg.PkgRepos, args, false, switchFlag)
+		if err := PrintResults(pkgs, errs); err != nil {
+			return err
 		}
-		if len(args) != len(pkgs) {
-			return errors.New("Not all packages installed successfully")
+		if len(errs) != 0 {
+			return ui.Errorf(ui.CodeInstallFailed, "Not all packages installed successfully")
 		}
-		color.Green("All %d packages are installed!", len(args))
+		color.Green("All %d packages are installed!", len(pkgs))
 		return nil
 	},
 }
 
 func init() {
-	AddCmd.Flags().BoolVar(&doRefresh, "refresh", false, "force refresh of package recipes")
 	AddCmd.Flags().BoolVar(&switchFlag, "switch", false, "switch to use this new package version")
 }
 
+// PkgJSONResult is the machine-readable result of installing a package
+type PkgJSONResult struct {
+	Package string   `json:"package"`
+	Version string   `json:"version,omitempty"`
+	Status  string   `json:"status"`
+	Notes   []string `json:"notes,omitempty"`
+	// RequiredBy lists the packages a dependency was installed for
+	RequiredBy []string      `json:"required_by,omitempty"`
+	Error      *ui.JSONError `json:"error,omitempty"`
+}
+
+// PrintResults prints the install notes of installed packages,
+// or a JSON document of all results and failures when using JSON output
+func PrintResults(pkgs []PkgInstallResult, errs []PkgInstallError) error {
+	if !ui.IsJSONOutput() {
+		for _, pkg := range pkgs {
+			if len(pkg.RequiredBy) != 0 && !pkg.AlreadyInstalled {
+				fmt.Printf("Installed %s as a dependency of %s\n",
+					color.CyanString(pkg.Name), strings.Join(pkg.RequiredBy, ", "))
+			}
+			fmt.Print(pkg.PkgConf.InstallNotes())
+		}
+		return nil
+	}
+	results := make([]PkgJSONResult, 0, len(pkgs)+len(errs))
+	for _, pkg := range pkgs {
+		status := "installed"
+		if pkg.AlreadyInstalled {
+			status = "already_installed"
+		}
+		var notes []string
+		for _, note := range []string{pkg.PkgConf.InstallNote, pkg.PkgConf.MyOsInfo().InstallNote} {
+			if note != "" {
+				notes = append(notes, note)
+			}
+		}
+		results = append(results, PkgJSONResult{
+			Package:    pkg.Name,
+			Version:    pkg.Ver,
+			Status:     status,
+			Notes:      notes,
+			RequiredBy: pkg.RequiredBy,
+		})
+	}
+	for _, pkgErr := range errs {
+		results = append(results, PkgJSONResult{
+			Package: pkgErr.Arg,
+			Status:  "failed",
+			Error:   ui.NewJSONError(pkgErr.Err),
+		})
+	}
+	return ui.PrintJSON(struct {
+		Packages []PkgJSONResult `json:"packages"`
+	}{results})
+}
+
 func CleanUpFailedInstall(pkg string, extractPath string) {
 	os.RemoveAll(extractPath)
 	pkgDir := filepath.Join(utils.WebmanPkgDir, pkg)
@@ -0,0 +1,161 @@
+package add
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/candrewlee14/webman/config"
+	"github.com/candrewlee14/webman/pkgparse"
+	"github.com/candrewlee14/webman/utils"
+)
+
+// installNode is a package to install, either requested or pulled in as a dependency
+type installNode struct {
+	// arg is passed to InstallPkg, like "go", "go@1.22.1" or "repo/go"
+	arg        string
+	pkg        string
+	requested  bool
+	requiredBy []string
+	deps       []*installNode
+	err        error
+	level      int
+	visiting   bool
+	visited    bool
+}
+
+// dependsLookup returns the dependencies listed in the recipe of a package
+type dependsLookup func(repo string, pkg string) ([]string, error)
+
+// installedCheck determines whether a package is installed, at a specific version if one is given
+type installedCheck func(pkg string, ver string) bool
+
+// planInstall resolves the dependencies of the requested packages, pulling in dependencies
+// that aren't installed. It returns the packages to install grouped in levels,
+// where each level only depends on packages in earlier levels.
+// Packages in a dependency cycle are returned with an error.
+func planInstall(args []string, lookup dependsLookup, isInstalled installedCheck) [][]*installNode {
+	var nodes []*installNode
+	byName := make(map[string]*installNode)
+	for _, arg := range args {
+		node := &installNode{arg: arg, requested: true}
+		if _, pkg, _, err := utils.ParsePkgVer(arg); err == nil {
+			node.pkg = pkg
+			if _, ok := byName[pkg]; !ok {
+				byName[pkg] = node
+			}
+		}
+		nodes = append(nodes, node)
+	}
+
+	var stack []*installNode
+	var visit func(node *installNode)
+	visit = func(node *installNode) {
+		node.visiting = true
+		stack = append(stack, node)
+		defer func() {
+			stack = stack[:len(stack)-1]
+			node.visiting = false
+			node.visited = true
+		}()
+		repo, pkg, _, err := utils.ParsePkgVer(node.arg)
+		if err != nil {
+			// InstallPkg reports invalid arguments
+			return
+		}
+		// a missing recipe is reported by InstallPkg, so there are just no dependencies to resolve
+		depends, _ := lookup(repo, pkg)
+		for _, dependArg := range depends {
+			_, depPkg, depVer, err := utils.ParsePkgVer(dependArg)
+			if err != nil {
+				node.err = fmt.Errorf("invalid dependency %q: %v", dependArg, err)
+				return
+			}
+			dep, ok := byName[depPkg]
+			if !ok {
+				if isInstalled(depPkg, depVer) {
+					continue
+				}
+				dep = &installNode{arg: dependArg, pkg: depPkg}
+				byName[depPkg] = dep
+				nodes = append(nodes, dep)
+			}
+			if dep.visiting {
+				cycle := []string{}
+				for i := len(stack) - 1; i >= 0; i-- {
+					cycle = append([]string{stack[i].pkg}, cycle...)
+					if stack[i] == dep {
+						break
+					}
+				}
+				cycle = append(cycle, dep.pkg)
+				node.err = fmt.Errorf("dependency cycle: %s", strings.Join(cycle, " -> "))
+				return
+			}
+			if !dep.visited {
+				visit(dep)
+			}
+			dep.requiredBy = append(dep.requiredBy, node.pkg)
+			node.deps = append(node.deps, dep)
+		}
+	}
+	for _, node := range nodes {
+		if node.requested && !node.visited {
+			visit(node)
+		}
+	}
+
+	var levelOf func(node *installNode) int
+	levelOf = func(node *installNode) int {
+		if node.level > 0 || len(node.deps) == 0 {
+			return node.level
+		}
+		for _, dep := range node.deps {
+			if level := levelOf(dep) + 1; level > node.level {
+				node.level = level
+			}
+		}
+		return node.level
+	}
+	var levels [][]*installNode
+	for _, node := range nodes {
+		level := levelOf(node)
+		for len(levels) <= level {
+			levels = append(levels, nil)
+		}
+		levels[level] = append(levels[level], node)
+	}
+	return levels
+}
+
+// recipeDepends looks up the dependencies of a package in the recipes of the given repos
+func recipeDepends(pkgRepos []*config.PkgRepo) dependsLookup {
+	return func(repo string, pkg string) ([]string, error) {
+		repos, err := config.SelectRepos(pkgRepos, repo)
+		if err != nil {
+			return nil, err
+		}
+		pkgConf, err := pkgparse.ParsePkgConfigLocal(repos, pkg)
+		if err != nil {
+			return nil, err
+		}
+		return pkgConf.Depends, nil
+	}
+}
+
+// isPkgInstalled determines whether any version of a package is installed, or a version matching the given one
+func isPkgInstalled(pkg string, ver string) bool {
+	stems, err := utils.InstalledPkgVerStems(pkg)
+	if err != nil {
+		return false
+	}
+	if ver == "" {
+		return len(stems) != 0
+	}
+	for _, stem := range stems {
+		_, installedVer := utils.ParseStem(stem)
+		if utils.SatisfiesVersion(ver, installedVer) {
+			return true
+		}
+	}
+	return false
+}
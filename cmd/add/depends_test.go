@@ -0,0 +1,56 @@
+package add
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func levelArgs(levels [][]*installNode) [][]string {
+	var args [][]string
+	for _, level := range levels {
+		var levelArgs []string
+		for _, node := range level {
+			levelArgs = append(levelArgs, node.arg)
+		}
+		args = append(args, levelArgs)
+	}
+	return args
+}
+
+func TestPlanInstall(t *testing.T) {
+	assert := is.New(t)
+
+	recipes := map[string][]string{
+		"app":  {"lib", "tool@1.2.0"},
+		"tool": {"lib"},
+		"a":    {"b"},
+		"b":    {"a"},
+	}
+	lookup := func(repo string, pkg string) ([]string, error) {
+		return recipes[pkg], nil
+	}
+	installed := func(pkg string, ver string) bool {
+		return pkg == "old"
+	}
+
+	levels := planInstall([]string{"app", "lib"}, lookup, installed)
+	assert.Equal(levelArgs(levels), [][]string{{"lib"}, {"tool@1.2.0"}, {"app"}}) // Dependencies should come first, deduplicated
+	assert.Equal(levels[1][0].requiredBy, []string{"app"})                        // Dependency should know what pulled it in
+	assert.True(levels[0][0].requested)                                           // Requested dependency should stay requested
+
+	recipes["app"] = []string{"old"}
+	levels = planInstall([]string{"app"}, lookup, installed)
+	assert.Equal(levelArgs(levels), [][]string{{"app"}}) // Installed dependencies should be skipped
+
+	levels = planInstall([]string{"a"}, lookup, installed)
+	var failed []string
+	for _, level := range levels {
+		for _, node := range level {
+			if node.err != nil {
+				failed = append(failed, node.pkg+": "+node.err.Error())
+			}
+		}
+	}
+	assert.Equal(failed, []string{"b: dependency cycle: a -> b -> a"}) // Cycle should be reported
+}
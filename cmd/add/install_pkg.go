@@ -3,9 +3,7 @@ package add
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"strings"
 	"sync"
 
 	"github.com/candrewlee14/webman/cmd/remove"
@@ -13,6 +11,7 @@ import (
 	"github.com/candrewlee14/webman/link"
 	"github.com/candrewlee14/webman/multiline"
 	"github.com/candrewlee14/webman/pkgparse"
+	"github.com/candrewlee14/webman/ui"
 	"github.com/candrewlee14/webman/unpack"
 	"github.com/candrewlee14/webman/utils"
 
@@ -20,33 +19,85 @@ import (
 )
 
 type PkgInstallResult struct {
-	Name    string
-	Ver     string
-	PkgConf *pkgparse.PkgConfig
+	Name             string
+	Ver              string
+	PkgConf          *pkgparse.PkgConfig
+	AlreadyInstalled bool
+	// RequiredBy lists the packages this one was pulled in for, if it wasn't requested itself
+	RequiredBy []string
 }
 
-func InstallAllPkgs(pkgRepos []*config.PkgRepo, args []string, removeOld bool, switchFlag bool) []PkgInstallResult {
-	var wg sync.WaitGroup
-	ml := multiline.New(len(args), os.Stdout)
-	wg.Add(len(args))
-	results := make(chan *PkgInstallResult, len(args))
-	for i, arg := range args {
-		i := i
-		arg := arg
-		go func() {
-			res := InstallPkg(pkgRepos, arg, i, len(args), &wg, &ml, removeOld, switchFlag)
-			results <- res
-		}()
-	}
-	wg.Wait()
-	pkgs := make([]PkgInstallResult, 0, len(args))
-	for i := 0; i < len(args); i++ {
-		res := <-results
-		if res != nil {
-			pkgs = append(pkgs, *res)
-		}
-	}
-	return pkgs
+// PkgInstallError is a package that failed to install
+type PkgInstallError struct {
+	Arg string
+	Err error
+}
+
+// InstallAllPkgs installs packages along with any dependencies that aren't installed yet.
+// Dependencies are installed before the packages that need them, and packages that don't
+// depend on each other are installed concurrently. Results and failures are returned in install order.
+func InstallAllPkgs(pkgRepos []*config.PkgRepo, args []string, removeOld bool, switchFlag bool) ([]PkgInstallResult, []PkgInstallError) {
+	levels := planInstall(args, recipeDepends(pkgRepos), isPkgInstalled)
+	var ordered []*installNode
+	for _, level := range levels {
+		ordered = append(ordered, level...)
+	}
+	lines := make(map[*installNode]int, len(ordered))
+	for i, node := range ordered {
+		lines[node] = i
+	}
+	ml := multiline.New(len(ordered), os.Stdout)
+	results := make(map[*installNode]*PkgInstallResult, len(ordered))
+	errs := make(map[*installNode]error, len(ordered))
+	for _, level := range levels {
+		var wg sync.WaitGroup
+		levelResults := make([]*PkgInstallResult, len(level))
+		levelErrs := make([]error, len(level))
+		for i, node := range level {
+			if node.err == nil {
+				for _, dep := range node.deps {
+					if errs[dep] != nil {
+						node.err = ui.Errorf(ui.CodeInstallFailed, "dependency %s failed to install", dep.pkg)
+						break
+					}
+				}
+			}
+			if node.err != nil {
+				ml.SetPrefix(lines[node], color.CyanString(node.arg)+": ")
+				ml.Printf(lines[node], color.RedString("%v", node.err))
+				levelErrs[i] = node.err
+				continue
+			}
+			i := i
+			node := node
+			wg.Add(1)
+			go func() {
+				levelResults[i], levelErrs[i] = InstallPkg(pkgRepos, node.arg, lines[node], len(ordered), &wg, &ml, removeOld, switchFlag)
+			}()
+		}
+		wg.Wait()
+		for i, node := range level {
+			if levelErrs[i] != nil {
+				errs[node] = levelErrs[i]
+			} else {
+				results[node] = levelResults[i]
+			}
+		}
+	}
+	pkgs := make([]PkgInstallResult, 0, len(ordered))
+	var pkgErrs []PkgInstallError
+	for _, node := range ordered {
+		if err := errs[node]; err != nil {
+			pkgErrs = append(pkgErrs, PkgInstallError{Arg: node.arg, Err: err})
+			continue
+		}
+		result := *results[node]
+		if !node.requested {
+			result.RequiredBy = node.requiredBy
+		}
+		pkgs = append(pkgs, result)
+	}
+	return pkgs, pkgErrs
 }
 
 func InstallPkg(
@@ -55,12 +106,20 @@ func InstallPkg(
 	wg *sync.WaitGroup, ml *multiline.MultiLogger,
 	removeOld bool,
 	switchFlag bool,
-) *PkgInstallResult {
+) (*PkgInstallResult, error) {
 	defer wg.Done()
-	pkg, ver, err := utils.ParsePkgVer(arg)
+	fail := func(code ui.ErrorCode, format string, a ...any) (*PkgInstallResult, error) {
+		err := ui.Errorf(code, format, a...)
+		ml.Printf(argIndex, color.RedString("%v", err))
+		return nil, err
+	}
+	repo, pkg, ver, err := utils.ParsePkgVer(arg)
 	if err != nil {
-		ml.Printf(argIndex, color.RedString(err.Error()))
-		return nil
+		return fail(ui.CodeInvalidArgs, "%v", err)
+	}
+	pkgRepos, err = config.SelectRepos(pkgRepos, repo)
+	if err != nil {
+		return fail(ui.CodeInvalidArgs, "%v", err)
 	}
 	if len(ver) == 0 {
 		ml.SetPrefix(argIndex, color.CyanString(pkg)+": ")
@@ -76,17 +135,29 @@ func InstallPkg(
 	pkgConf, err := pkgparse.ParsePkgConfigLocal(pkgRepos, pkg)
 	foundRecipe <- true
 	if err != nil {
-		ml.Printf(argIndex, color.RedString("%v", err))
-		return nil
+		return fail(ui.CodeRecipeNotFound, "%v", err)
 	}
 	pkgOS := pkgparse.GOOStoPkgOs[utils.GOOS]
 	for _, ignorePair := range pkgConf.Ignore {
 		if pkgOS == ignorePair.Os && utils.GOARCH == ignorePair.Arch {
-			ml.Printf(argIndex, color.RedString("unsupported OS + Arch for this package"))
-			return nil
+			return fail(ui.CodeUnsupportedPlatform, "unsupported OS + Arch for this package")
 		}
 	}
-	if len(ver) == 0 || pkgConf.ForceLatest {
+	if utils.IsVersionConstraint(ver) && !pkgConf.ForceLatest {
+		foundMatching := make(chan bool)
+		ml.PrintUntilDone(argIndex,
+			fmt.Sprintf("Finding %s version matching %s", color.CyanString(pkg), color.MagentaString(ver)),
+			foundMatching,
+			50,
+		)
+		verPtr, err := pkgConf.GetMatchingVersion(ver)
+		foundMatching <- true
+		if err != nil {
+			return fail(ui.CodeVersionNotFound, "%v", err)
+		}
+		ver = *verPtr
+		ml.Printf(argIndex, "Found %s version tag: %s", color.CyanString(pkg), color.MagentaString(ver))
+	} else if len(ver) == 0 || pkgConf.ForceLatest {
 		foundLatest := make(chan bool)
 		ml.PrintUntilDone(argIndex,
 			fmt.Sprintf("Finding latest %s version tag", color.CyanString(pkg)),
@@ -96,21 +167,22 @@ func InstallPkg(
 		verPtr, err := pkgConf.GetLatestVersion()
 		foundLatest <- true
 		if err != nil {
-			ml.Printf(argIndex, color.RedString("unable to find latest version tag: %v", err))
-			return nil
+			return fail(ui.CodeVersionNotFound, "unable to find latest version tag: %v", err)
 		}
-		if pkgConf.ForceLatest && len(ver) != 0 && *verPtr != ver {
-			ml.Printf(argIndex, color.RedString("This package requires using the latest version, which is currently %s",
-				color.MagentaString(*verPtr)))
-			return nil
+		if pkgConf.ForceLatest && len(ver) != 0 && *verPtr != ver && !(utils.IsVersionConstraint(ver) && utils.MatchVersion(ver, *verPtr)) {
+			return fail(ui.CodeVersionNotFound, "This package requires using the latest version, which is currently %s",
+				color.MagentaString(*verPtr))
 		}
 		ver = *verPtr
 		ml.Printf(argIndex, "Found %s version tag: %s", color.CyanString(pkg), color.MagentaString(ver))
 	}
+	osInfo, err := pkgConf.GetMyOsInfo()
+	if err != nil {
+		return fail(ui.CodeUnsupportedPlatform, "%v", err)
+	}
 	stemPtr, extPtr, urlPtr, err := pkgConf.GetAssetStemExtUrl(ver)
 	if err != nil {
-		ml.Printf(argIndex, color.RedString("%v", err))
-		return nil
+		return fail(ui.CodeUnsupportedPlatform, "%v", err)
 	}
 	stem := *stemPtr
 	ext := *extPtr
@@ -125,33 +197,28 @@ func InstallPkg(
 	extractStem := utils.CreateStem(pkg, ver)
 	extractPath := filepath.Join(utils.WebmanPkgDir, pkg, extractStem)
 
+	alreadyInstalled := false
 	// If file exists
 	if _, err := os.Stat(extractPath); !os.IsNotExist(err) {
+		alreadyInstalled = true
 		ml.Printf(argIndex, color.HiBlackString("Already installed!"))
 	} else {
 		if !DownloadUrl(url, downloadPath, pkg, ver, argIndex, argCount, ml) {
-			return nil
-		}
-		var isRawBinary bool
-		if m, ok := pkgConf.OsMap[pkgOS]; ok {
-			isRawBinary = m.IsRawBinary
+			return nil, ui.Errorf(ui.CodeDownloadFailed, "unable to download %s@%s from %s", pkg, ver, url)
 		}
-		if isRawBinary {
+		if osInfo.IsRawBinary {
 			if err = os.Chmod(downloadPath, 0o755); err != nil {
-				ml.Printf(argIndex, color.RedString("Failed to make download executable!"))
-				return nil
+				return fail(ui.CodeUnpackFailed, "Failed to make download executable!")
 			}
 			if err = os.MkdirAll(extractPath, os.ModePerm); err != nil {
-				ml.Printf(argIndex, color.RedString("Failed to create package-version path!"))
-				return nil
+				return fail(ui.CodeUnpackFailed, "Failed to create package-version path!")
 			}
 			binPath := filepath.Join(extractPath, pkgConf.Title)
 			if utils.GOOS == "windows" {
 				binPath += ".exe"
 			}
 			if err = os.Rename(downloadPath, binPath); err != nil {
-				ml.Printf(argIndex, color.RedString("Failed to rename temporary download to new path!"))
-				return nil
+				return fail(ui.CodeUnpackFailed, "Failed to rename temporary download to new path!")
 			}
 		} else {
 			hasUnpacked := make(chan bool)
@@ -160,16 +227,11 @@ func InstallPkg(
 				hasUnpacked,
 				50,
 			)
-			var extractHasRoot bool
-			if m, ok := pkgConf.OsMap[pkgOS]; ok {
-				extractHasRoot = m.ExtractHasRoot
-			}
-			err = unpack.Unpack(downloadPath, pkg, extractStem, extractHasRoot)
+			err = unpack.Unpack(downloadPath, pkg, extractStem, osInfo.ExtractHasRoot)
 			hasUnpacked <- true
 			if err != nil {
-				ml.Printf(argIndex, color.RedString("%v", err))
 				CleanUpFailedInstall(pkg, extractPath)
-				return nil
+				return fail(ui.CodeUnpackFailed, "%v", err)
 			}
 			ml.Printf(argIndex, "Completed unpacking %s@%s", color.CyanString(pkg), color.MagentaString(ver))
 		}
@@ -177,9 +239,8 @@ func InstallPkg(
 
 	using, err := pkgparse.CheckUsing(pkg)
 	if err != nil {
-		ml.Printf(argIndex, color.RedString("Failed to check using: %v", err))
 		CleanUpFailedInstall(pkg, extractPath)
-		return nil
+		return fail(ui.CodeUnknown, "Failed to check using: %v", err)
 	}
 	usingVer := ""
 	if using != nil {
@@ -187,10 +248,15 @@ func InstallPkg(
 	}
 	// if not already installed, or already installed but not using the same version
 	// we'll need to remove the old and link the new
+	linked := false
+	var linkPaths []string
 	if using == nil || usingVer != ver {
 		if using != nil {
 			if removeOld {
-				if err = remove.RemovePkgVer(*using, using, pkg, pkgConf); err != nil {
+				err = remove.RemovePkgVer(*using, using, pkg, pkgConf, func(format string, a ...any) {
+					ml.Printf(argIndex, format, a...)
+				})
+				if err != nil {
 					ml.Printf(argIndex, color.RedString("Failed to remove old version: %v", err))
 				} else {
 					ml.Printf(argIndex, "Removed old version %s", color.CyanString(*using))
@@ -201,31 +267,64 @@ func InstallPkg(
 			binPaths, err := pkgConf.GetMyBinPaths()
 			if err != nil {
 				CleanUpFailedInstall(pkg, extractPath)
-				ml.Printf(argIndex, color.RedString("%v", err))
-				return nil
+				return fail(ui.CodeUnsupportedPlatform, "%v", err)
 			}
 			renames, err := pkgConf.GetRenames()
 			if err != nil {
-				ml.Printf(argIndex, color.RedString("Failed creating links: %v", err))
-				return nil
+				return fail(ui.CodeLinkFailed, "Failed creating links: %v", err)
 			}
 			madeLinks, err := link.CreateLinks(pkg, ver, binPaths, renames)
 			if err != nil {
 				CleanUpFailedInstall(pkg, extractPath)
-				ml.Printf(argIndex, color.RedString("Failed creating links: %v", err))
-				return nil
+				return fail(ui.CodeLinkFailed, "Failed creating links: %v", err)
 			}
 			if !madeLinks {
 				CleanUpFailedInstall(pkg, extractPath)
-				ml.Printf(argIndex, color.RedString("Failed creating links"))
-				return nil
+				return fail(ui.CodeLinkFailed, "Failed creating links")
+			}
+			if _, linkPaths, err = link.GetBinPathsAndLinkPaths(pkg, ver, binPaths, renames); err != nil {
+				return fail(ui.CodeLinkFailed, "Failed finding links: %v", err)
 			}
+			if err = pkgConf.WriteUsingEnv(extractStem); err != nil {
+				return fail(ui.CodeLinkFailed, "Failed recording environment variables: %v", err)
+			}
+			linked = true
 			ml.Printf(argIndex, "Now using %s@%s", color.CyanString(pkg), color.MagentaString(ver))
 		}
+		var hookErr error
+		if !alreadyInstalled {
+			hookErr = pkgConf.RunHooks(pkgConf.PostInstallHooks(), extractStem, func(format string, a ...any) {
+				ml.Printf(argIndex, format, a...)
+			})
+		}
+		// completions generated by hooks are linked too, so this comes after them
+		if linked {
+			shareLinks, err := link.CreateShareLinks(pkgConf, extractStem)
+			if err != nil {
+				ml.Printf(argIndex, color.YellowString("Failed linking completions and man pages: %v", err))
+			} else if hint := link.ShareHint(shareLinks); hint != "" {
+				ml.Printf(argIndex, "%s", color.HiBlackString(hint))
+			}
+		}
 		ml.Printf(argIndex, color.GreenString("Successfully installed!"))
+		if hookErr != nil {
+			ml.Printf(argIndex, color.RedString("Installed, but a post-install hook failed: %v", hookErr))
+		}
+	}
+	if !alreadyInstalled {
+		if err = pkgparse.WriteInstalled(pkg, extractStem, pkgparse.InstallInfo{Repo: pkgConf.Repo}); err != nil {
+			return fail(ui.CodeInstallFailed, "Failed to record installed version: %v", err)
+		}
 	}
-	if p, err := exec.LookPath(pkg); err == nil && !strings.Contains(p, utils.WebmanBinDir) {
-		ml.Printf(argIndex, color.YellowString("Found another binary at %q that may interfere", p))
+	// the recipe is kept for removing and switching to this version if it's later gone from its repo
+	if err = pkgparse.SaveRecipe(pkgConf, extractStem); err != nil {
+		ml.Printf(argIndex, color.YellowString("Failed to keep a copy of the recipe: %v", err))
+	}
+	// the links can be named differently from the package, or there can be several of them
+	for _, linkPath := range linkPaths {
+		for _, shadow := range link.Shadows(filepath.Base(linkPath)) {
+			ml.Printf(argIndex, color.YellowString("Found another binary at %q that may interfere with %s", shadow, filepath.Base(linkPath)))
+		}
 	}
-	return &PkgInstallResult{pkg, ver, pkgConf}
+	return &PkgInstallResult{Name: pkg, Ver: ver, PkgConf: pkgConf, AlreadyInstalled: alreadyInstalled}, nil
 }
@@ -5,13 +5,20 @@ import (
 	"github.com/candrewlee14/webman/cmd/config"
 	"github.com/candrewlee14/webman/cmd/dev"
 	"github.com/candrewlee14/webman/cmd/doctor"
+	"github.com/candrewlee14/webman/cmd/env"
+	"github.com/candrewlee14/webman/cmd/gc"
 	"github.com/candrewlee14/webman/cmd/group"
+	"github.com/candrewlee14/webman/cmd/hold"
+	"github.com/candrewlee14/webman/cmd/info"
 	"github.com/candrewlee14/webman/cmd/remove"
+	"github.com/candrewlee14/webman/cmd/repo"
 	"github.com/candrewlee14/webman/cmd/run"
 	"github.com/candrewlee14/webman/cmd/search"
+	"github.com/candrewlee14/webman/cmd/shellinit"
 	switchcmd "github.com/candrewlee14/webman/cmd/switch"
 	"github.com/candrewlee14/webman/cmd/upgrade"
 	"github.com/candrewlee14/webman/cmd/version"
+	"github.com/candrewlee14/webman/cmd/which"
 )
 
 func init() {
@@ -19,11 +26,20 @@ func init() {
 	rootCmd.AddCommand(config.ConfigCmd)
 	rootCmd.AddCommand(dev.DevCmd)
 	rootCmd.AddCommand(doctor.DoctorCmd)
+	rootCmd.AddCommand(env.EnvCmd)
+	rootCmd.AddCommand(gc.GcCmd)
 	rootCmd.AddCommand(remove.RemoveCmd)
+	rootCmd.AddCommand(repo.RepoCmd)
 	rootCmd.AddCommand(run.RunCmd)
 	rootCmd.AddCommand(switchcmd.SwitchCmd)
 	rootCmd.AddCommand(group.GroupCmd)
+	rootCmd.AddCommand(hold.HoldCmd)
+	rootCmd.AddCommand(hold.UnholdCmd)
+	rootCmd.AddCommand(info.InfoCmd)
 	rootCmd.AddCommand(search.SearchCmd)
+	rootCmd.AddCommand(shellinit.ShellInitCmd)
 	rootCmd.AddCommand(version.VersionCmd)
 	rootCmd.AddCommand(upgrade.UpgradeCmd)
+	rootCmd.AddCommand(which.WhichCmd)
+	rootCmd.AddCommand(which.WhereCmd)
 }
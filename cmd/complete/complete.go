@@ -0,0 +1,138 @@
+package complete
+
+import (
+	"strings"
+
+	"github.com/candrewlee14/webman/config"
+	"github.com/candrewlee14/webman/pkgparse"
+	"github.com/candrewlee14/webman/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// Func is a cobra completion function for ValidArgsFunction
+type Func = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// Packages completes package names from the recipe repositories, and their released versions after "pkg@"
+func Packages(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	if pkgArg, verPrefix, found := strings.Cut(toComplete, "@"); found {
+		return releaseVersions(cfg, pkgArg, verPrefix)
+	}
+	idx, err := pkgparse.LoadIndex(cfg.PkgRepos)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var pkgs []string
+	seen := make(map[string]bool)
+	for _, entry := range idx.Pkgs() {
+		if !seen[entry.Title] && strings.HasPrefix(entry.Title, toComplete) {
+			seen[entry.Title] = true
+			pkgs = append(pkgs, entry.Title+"\t"+entry.Tagline)
+		}
+	}
+	return pkgs, cobra.ShellCompDirectiveNoFileComp
+}
+
+// releaseVersions completes the released versions of a package, which are cached between completions
+func releaseVersions(cfg *config.Config, pkgArg string, verPrefix string) ([]string, cobra.ShellCompDirective) {
+	repo, pkg, _, err := utils.ParsePkgVer(pkgArg)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	repos, err := config.SelectRepos(cfg.PkgRepos, repo)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	pkgConf, err := pkgparse.ParsePkgConfigLocal(repos, pkg)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	versions, err := pkgConf.GetCachedVersions()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var completions []string
+	for _, ver := range versions {
+		if strings.HasPrefix(ver, verPrefix) {
+			completions = append(completions, pkgArg+"@"+ver)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// UpgradablePackages completes installed package names, and their released versions after "pkg@"
+func UpgradablePackages(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if strings.Contains(toComplete, "@") {
+		return Packages(cmd, args, toComplete)
+	}
+	return InstalledPackages(cmd, args, toComplete)
+}
+
+// InstalledPackages completes installed package names, and their installed versions after "pkg@"
+func InstalledPackages(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if pkg, verPrefix, found := strings.Cut(toComplete, "@"); found {
+		var completions []string
+		for _, ver := range InstalledVersions(pkg) {
+			if strings.HasPrefix(ver, verPrefix) {
+				completions = append(completions, pkg+"@"+ver)
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+	var pkgs []string
+	for _, pkg := range utils.InstalledPackages() {
+		if strings.HasPrefix(pkg, toComplete) {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	return pkgs, cobra.ShellCompDirectiveNoFileComp
+}
+
+// InstalledVersions returns the installed versions of a package, newest first
+func InstalledVersions(pkg string) []string {
+	stems, err := utils.InstalledPkgVerStems(pkg)
+	if err != nil {
+		return nil
+	}
+	var vers []string
+	for i := len(stems) - 1; i >= 0; i-- {
+		_, ver := utils.ParseStem(stems[i])
+		vers = append(vers, ver)
+	}
+	return vers
+}
+
+// Groups completes package group names, including local groups
+func Groups(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	idx, err := pkgparse.LoadIndex(cfg.PkgRepos)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var groups []string
+	seen := make(map[string]bool)
+	for _, entry := range idx.Groups() {
+		if !seen[entry.Name] && strings.HasPrefix(entry.Name, toComplete) {
+			seen[entry.Name] = true
+			groups = append(groups, entry.Name+"\t"+entry.Tagline)
+		}
+	}
+	return groups, cobra.ShellCompDirectiveNoFileComp
+}
+
+// Single limits a completion function to the first argument
+func Single(complete Func) Func {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) != 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return complete(cmd, args, toComplete)
+	}
+}
@@ -32,7 +32,9 @@ The "config add" subcommand allows you to add a package repository.
 				Prompt: &survey.Input{
 					Message: "Repository name",
 				},
-				Validate: survey.Required,
+				Validate: survey.ComposeValidators(survey.Required, func(ans any) error {
+					return config.ValidateRepoName(fmt.Sprint(ans))
+				}),
 			},
 			{
 				Name: "type",
@@ -63,6 +65,20 @@ The "config add" subcommand allows you to add a package repository.
 					Default: "main",
 				},
 			},
+			{
+				Name: "ref",
+				Prompt: &survey.Input{
+					Message: "Git tag or commit to pin to",
+					Help:    "Leave empty to follow the branch",
+				},
+			},
+			{
+				Name: "allow_hooks",
+				Prompt: &survey.Confirm{
+					Message: "Allow recipes from this repository to run install and remove hooks?",
+					Help:    "Hooks run commands and write files on your machine, so only allow repositories you trust",
+				},
+			},
 		}
 
 		if err := survey.Ask(qs, &repo); err != nil {
@@ -88,7 +104,7 @@ The "config add" subcommand allows you to add a package repository.
 			return fmt.Errorf("%q is an invalid package repository; no `pkgs` sub-directory", p.Name)
 		}
 
-		if err := p.RefreshRecipes(); err != nil {
+		if _, err := config.RefreshRepos([]*config.PkgRepo{&p}); err != nil {
 			return err
 		}
 
@@ -119,6 +135,10 @@ func (p *PkgRepo) WriteAnswer(field string, value any) error {
 		p.Repo = fmt.Sprint(value)
 	case "branch":
 		p.Branch = fmt.Sprint(value)
+	case "ref":
+		p.Ref = fmt.Sprint(value)
+	case "allow_hooks":
+		p.AllowHooks = value.(bool)
 	default:
 		return errors.New("unknown field")
 	}
@@ -2,9 +2,14 @@ package remove
 
 import (
 	"errors"
-	"os"
+	"fmt"
+	"strings"
 
+	removecmd "github.com/candrewlee14/webman/cmd/remove"
 	"github.com/candrewlee14/webman/config"
+	"github.com/candrewlee14/webman/pkgparse"
+	"github.com/candrewlee14/webman/ui"
+	"github.com/candrewlee14/webman/utils"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/fatih/color"
@@ -18,6 +23,7 @@ var RemoveCmd = &cobra.Command{
 	Long: `
 
 The "config remove" subcommand allows you to remove a package repository.
+If packages were installed from it, you are asked whether to uninstall them too.
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg, err := config.Load()
@@ -44,19 +50,26 @@ The "config remove" subcommand allows you to remove a package repository.
 			return err
 		}
 
-		var remove *config.PkgRepo
-		for idx, pkgRepo := range cfg.PkgRepos {
-			if pkgRepo.Name == pkg {
-				remove = pkgRepo
-				cfg.PkgRepos = append(cfg.PkgRepos[:idx], cfg.PkgRepos[idx+1:]...)
-				break
+		if installed := pkgparse.InstalledFromRepo(cfg.PkgRepos, pkg); len(installed) != 0 {
+			q := &survey.Confirm{
+				Message: fmt.Sprintf("Also uninstall packages installed from %q (%s)?", pkg, strings.Join(installed, ", ")),
 			}
-		}
-
-		if remove != nil {
-			if err := os.RemoveAll(remove.Path()); err != nil {
+			uninstall := false
+			if err := survey.AskOne(q, &uninstall); err != nil {
 				return err
 			}
+			if uninstall {
+				// packages are removed while the repo is still known, so their hooks can run
+				for _, installedPkg := range installed {
+					if err := removeFromRepo(cfg.PkgRepos, installedPkg, pkg); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		if err := cfg.RemoveRepo(pkg); err != nil {
+			return err
 		}
 
 		if err := cfg.Save(); err != nil {
@@ -67,3 +80,55 @@ The "config remove" subcommand allows you to remove a package repository.
 		return nil
 	},
 }
+
+// removeFromRepo removes the versions of a package that were installed from a repo,
+// leaving versions installed from other repos in place
+func removeFromRepo(pkgRepos []*config.PkgRepo, pkg string, repo string) error {
+	stems, err := pkgparse.InstalledStemsFromRepo(pkgRepos, pkg, repo)
+	if err != nil {
+		return err
+	}
+	allStems, err := utils.InstalledPkgVerStems(pkg)
+	if err != nil {
+		return err
+	}
+	if len(stems) == len(allStems) {
+		pkgConf, err := removecmd.InstalledPkgConf(pkgRepos, pkg)
+		if err != nil {
+			return err
+		}
+		if _, err := removecmd.RemoveAllVers(pkg, pkgConf); err != nil {
+			return err
+		}
+		fmt.Print(pkgConf.RemoveNotes())
+		fmt.Println("Removed", color.CyanString(pkg))
+		return nil
+	}
+	using, err := pkgparse.CheckUsing(pkg)
+	if err != nil {
+		return err
+	}
+	removedUsing := false
+	for _, stem := range stems {
+		removedUsing = removedUsing || (using != nil && *using == stem)
+		pkgConf, err := pkgparse.ParseInstalledPkgConfig(pkgRepos, pkg, stem)
+		if ui.CodeOf(err) == ui.CodeRecipeNotFound {
+			color.Yellow("No recipe found for %s; removing it without running hooks", stem)
+			pkgConf, err = &pkgparse.PkgConfig{Title: pkg}, nil
+		}
+		if err != nil {
+			return err
+		}
+		err = removecmd.RemovePkgVer(stem, using, pkg, pkgConf, func(format string, a ...any) {
+			fmt.Printf(format+"\n", a...)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if removedUsing {
+		color.Yellow("Removed the %s version in use; switch to another with \"webman switch %s\"", pkg, pkg)
+	}
+	fmt.Printf("Removed %s, keeping the versions installed from other repos\n", color.CyanString(strings.Join(stems, ", ")))
+	return nil
+}
@@ -0,0 +1,35 @@
+package remove
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/candrewlee14/webman/pkgparse"
+	"github.com/candrewlee14/webman/utils"
+
+	"github.com/matryer/is"
+)
+
+func TestRemoveFromRepo(t *testing.T) {
+	assert := is.New(t)
+
+	utils.Init(t.TempDir())
+	for stem, repo := range map[string]string{"foo-1.0.0": "webman", "foo-1.1.0": "other", "bar-1.0.0": "webman"} {
+		pkg, _ := utils.ParseStem(stem)
+		assert.NoErr(os.MkdirAll(filepath.Join(utils.WebmanPkgDir, pkg, stem), os.ModePerm)) // Should create version dir
+		assert.NoErr(pkgparse.WriteInstalled(pkg, stem, pkgparse.InstallInfo{Repo: repo}))   // Should record installed repo
+	}
+
+	assert.NoErr(removeFromRepo(nil, "foo", "webman")) // Should remove foo versions from the repo
+	stems, err := utils.InstalledPkgVerStems("foo")
+	assert.NoErr(err)                          // Should list remaining versions
+	assert.Equal(stems, []string{"foo-1.1.0"}) // Version from the other repo should be kept
+	info, err := pkgparse.CheckInstalled("foo", "foo-1.0.0")
+	assert.NoErr(err)        // Should read installed record
+	assert.True(info == nil) // Removed version should no longer be recorded
+
+	assert.NoErr(removeFromRepo(nil, "bar", "webman")) // Should remove bar entirely
+	_, err = os.Stat(filepath.Join(utils.WebmanPkgDir, "bar"))
+	assert.True(os.IsNotExist(err)) // Package with only versions from the repo should be removed
+}
@@ -29,7 +29,8 @@ var BintestCmd = &cobra.Command{
 	Use:   "bintest [pkg]",
 	Short: "Test the installation & binary paths for each platform for a package",
 	Long: `
-The "bintest" tests that binary paths given in a package recipe have valid binaries, and displays them.`,
+The "bintest" tests that binary paths given in a package recipe have valid binaries, and displays them.
+Recipes with os_map entries for a C library are tested with each C library on Linux.`,
 	Example: `webman dev bintest zoxide -l ~/repos/webman-pkgs/`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 1 {
@@ -61,10 +62,13 @@ The "bintest" tests that binary paths given in a package recipe have valid binar
 		for _, osStr := range OsOptions {
 			// Example: convert "windows" GOOS to "win" pkgOS
 			osPkgStr := pkgparse.GOOStoPkgOs[osStr]
+			libcs := []string{""}
+			if osStr == "linux" && pkgConf.HasLibcVariants() {
+				libcs = utils.Libcs
+			}
 		archLoop:
 			for _, arch := range ArchOptions {
 				fmt.Println("")
-				osPairStr := fmt.Sprintf("%s-%s", osStr, arch)
 				if _, osSupported := pkgConf.OsMap[osPkgStr]; !osSupported {
 					color.HiBlack("Skipping all %s: unsupported by %s", osStr, pkg)
 					continue osLoop
@@ -79,37 +83,47 @@ The "bintest" tests that binary paths given in a package recipe have valid binar
 						continue archLoop
 					}
 				}
-				fmt.Printf("Trying %s-%s installation\n", osStr, arch)
-				if err = InitTestDir(osStr, arch, homedir, testDir); err != nil {
-					return err
-				}
-				var wg sync.WaitGroup
-				ml := multiline.New(len(args), os.Stdout)
-				wg.Add(1)
-				pairResults[osPairStr] = add.InstallPkg(cfg.PkgRepos, pkg+"@"+*latestVer, 0, 1, &wg, &ml, false, false) != nil
+				for n, libc := range libcs {
+					osPairStr := fmt.Sprintf("%s-%s", osStr, arch)
+					if libc != "" {
+						osPairStr += "-" + libc
+					}
+					if n > 0 {
+						fmt.Println("")
+					}
+					fmt.Printf("Trying %s installation\n", osPairStr)
+					if err = InitTestDir(osStr, arch, libc, homedir, testDir); err != nil {
+						return err
+					}
+					var wg sync.WaitGroup
+					ml := multiline.New(len(args), os.Stdout)
+					wg.Add(1)
+					_, err = add.InstallPkg(cfg.PkgRepos, pkg+"@"+*latestVer, 0, 1, &wg, &ml, false, false)
+					pairResults[osPairStr] = err == nil
 
-				relbinPaths, err := pkgConf.GetMyBinPaths()
-				if err != nil {
-					color.Red("Error getting bin paths: %v", err)
-					pairResults[osPairStr] = false
-					continue
-				}
-				renames, err := pkgConf.GetRenames()
-				if err != nil {
-					return err
-				}
-				binPaths, _, err := link.GetBinPathsAndLinkPaths(pkg, *latestVer, relbinPaths, renames)
-				if err != nil {
-					color.Red("Error getting bin paths and link paths: %v", err)
-					pairResults[osPairStr] = false
-					continue
-				}
-				fmt.Println("  Installation Binary Paths:")
-				for i := range binPaths {
-					color.Magenta("   %s", binPaths[i])
-				}
-				if pairResults[osPairStr] {
-					os.RemoveAll(testDir)
+					relbinPaths, err := pkgConf.GetMyBinPaths()
+					if err != nil {
+						color.Red("Error getting bin paths: %v", err)
+						pairResults[osPairStr] = false
+						continue
+					}
+					renames, err := pkgConf.GetRenames()
+					if err != nil {
+						return err
+					}
+					binPaths, _, err := link.GetBinPathsAndLinkPaths(pkg, *latestVer, relbinPaths, renames)
+					if err != nil {
+						color.Red("Error getting bin paths and link paths: %v", err)
+						pairResults[osPairStr] = false
+						continue
+					}
+					fmt.Println("  Installation Binary Paths:")
+					for i := range binPaths {
+						color.Magenta("   %s", binPaths[i])
+					}
+					if pairResults[osPairStr] {
+						os.RemoveAll(testDir)
+					}
 				}
 			}
 		}
@@ -135,8 +149,8 @@ The "bintest" tests that binary paths given in a package recipe have valid binar
 	},
 }
 
-func InitTestDir(osStr string, arch string, homedir string, testdir string) error {
-	utils.WebmanDir = filepath.Join(testdir, osStr, arch)
+func InitTestDir(osStr string, arch string, libc string, homedir string, testdir string) error {
+	utils.WebmanDir = filepath.Join(testdir, osStr, arch, libc)
 	utils.WebmanPkgDir = filepath.Join(utils.WebmanDir, "/pkg")
 	utils.WebmanBinDir = filepath.Join(utils.WebmanDir, "/bin")
 	utils.WebmanTmpDir = filepath.Join(utils.WebmanDir, "/tmp")
@@ -153,5 +167,6 @@ func InitTestDir(osStr string, arch string, homedir string, testdir string) erro
 	}
 	utils.GOOS = osStr
 	utils.GOARCH = arch
+	utils.LIBC = libc
 	return nil
 }
@@ -108,10 +108,16 @@ func CheckGroup(path string) error {
 		return fmt.Errorf("no group file found for %s: %v", group, err)
 	}
 	for _, pkg := range groupConf.Packages {
-		if err := CheckPkgConfig(pkg); err != nil {
+		if err := CheckPkgConfig(pkg.Name); err != nil {
 			return err
 		}
 	}
+	for _, included := range groupConf.Groups {
+		includedPath := filepath.Join(filepath.Dir(path), included+utils.GroupRecipeExt)
+		if _, err := os.Stat(includedPath); err != nil {
+			return fmt.Errorf("included group %s not found: %v", included, err)
+		}
+	}
 	if _, err := fi.Seek(0, 0); err != nil {
 		return err
 	}
@@ -125,5 +131,15 @@ func CheckPkgConfig(pkg string) error {
 		return err
 	}
 	defer fi.Close()
-	return schema.LintRecipe(fi)
+	if err := schema.LintRecipe(fi); err != nil {
+		return err
+	}
+	if _, err := fi.Seek(0, 0); err != nil {
+		return err
+	}
+	pkgConf, err := pkgparse.ParsePkgConfig(pkg, fi)
+	if err != nil {
+		return err
+	}
+	return pkgConf.CheckTemplates()
 }
@@ -0,0 +1,121 @@
+package check
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/candrewlee14/webman/cmd/env"
+	"github.com/candrewlee14/webman/config"
+	"github.com/candrewlee14/webman/link"
+	"github.com/candrewlee14/webman/utils"
+)
+
+// BinPath checks that the bin directory is on the PATH, and that its binaries aren't shadowed by others
+var BinPath = Check{
+	Name: "Bin Path",
+	ID:   "bin_path",
+	Func: func(cfg *config.Config, fix bool, r *Report) error {
+		onPath := false
+		for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+			if filepath.Clean(dir) == utils.WebmanBinDir {
+				onPath = true
+				break
+			}
+		}
+		if !onPath {
+			issue := r.Issue("%q is not on the PATH", utils.WebmanBinDir)
+			profile, line, err := shellProfile(env.DetectShell())
+			if err != nil {
+				return err
+			}
+			if !fix {
+				r.Hint(issue, "add `%s` to %s, or run with --fix", line, profile)
+				return nil
+			}
+			if err := addToProfile(profile, line); err != nil {
+				r.FixFailed(issue, err)
+				return nil
+			}
+			r.Fixed(issue, "added `%s` to %s; open a new shell to apply it", line, profile)
+			return nil
+		}
+		r.OK("%q is on the PATH", utils.WebmanBinDir)
+
+		entries, err := os.ReadDir(utils.WebmanBinDir)
+		if err != nil {
+			return err
+		}
+		shadowed := 0
+		for _, entry := range entries {
+			name := entry.Name()
+			if utils.GOOS == "windows" {
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+			shadows := link.Shadows(name)
+			if len(shadows) == 0 {
+				continue
+			}
+			shadowed++
+			issue := r.Issue("%s is shadowed by %s", name, shadows[0])
+			// other tools own the earlier PATH entries, so they are left alone
+			r.Hint(issue, "put %q before %q on the PATH, or remove the other binary", utils.WebmanBinDir, filepath.Dir(shadows[0]))
+		}
+		if shadowed == 0 {
+			r.OK("no binaries are shadowed by other PATH entries")
+		}
+		return nil
+	},
+}
+
+// shellProfile returns the profile of a shell and the line that sets up webman in it
+func shellProfile(shell string) (string, string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", "", err
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return "", "", err
+	}
+	switch shell {
+	case "zsh":
+		dir := os.Getenv("ZDOTDIR")
+		if dir == "" {
+			dir = home
+		}
+		return filepath.Join(dir, ".zshrc"), fmt.Sprintf(`eval "$('%s' shell-init zsh)"`, exe), nil
+	case "fish":
+		return filepath.Join(home, ".config", "fish", "config.fish"), fmt.Sprintf(`'%s' shell-init fish | source`, exe), nil
+	case "powershell":
+		return filepath.Join(home, "Documents", "PowerShell", "Microsoft.PowerShell_profile.ps1"),
+			fmt.Sprintf(`& '%s' shell-init powershell | Out-String | Invoke-Expression`, exe), nil
+	default:
+		return filepath.Join(home, ".bashrc"), fmt.Sprintf(`eval "$('%s' shell-init bash)"`, exe), nil
+	}
+}
+
+// addToProfile appends a line to a shell profile, unless it already sets up webman
+func addToProfile(profile string, line string) error {
+	data, err := os.ReadFile(profile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if strings.Contains(string(data), "shell-init") {
+		return fmt.Errorf("%q already runs webman shell-init; open a new shell to apply it", profile)
+	}
+	if err := os.MkdirAll(filepath.Dir(profile), os.ModePerm); err != nil {
+		return err
+	}
+	fi, err := os.OpenFile(profile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer fi.Close()
+	if len(data) != 0 && !strings.HasSuffix(string(data), "\n") {
+		line = "\n" + line
+	}
+	_, err = fmt.Fprintf(fi, "%s\n", line)
+	return err
+}
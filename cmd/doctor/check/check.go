@@ -1,8 +1,119 @@
 package check
 
-import "github.com/candrewlee14/webman/config"
+import (
+	"fmt"
+
+	"github.com/candrewlee14/webman/config"
+	"github.com/candrewlee14/webman/ui"
+
+	"github.com/fatih/color"
+)
 
 type Check struct {
 	Name string
-	Func func(cfg *config.Config, fix bool) error
+	// ID identifies the check in JSON reports
+	ID string
+	// NeedsConfig checks are skipped when the config can't be loaded
+	NeedsConfig bool
+	Func        func(cfg *config.Config, fix bool, r *Report) error
+}
+
+// Check and issue statuses
+const (
+	StatusOK      = "ok"
+	StatusIssues  = "issues"
+	StatusFixed   = "fixed"
+	StatusSkipped = "skipped"
+)
+
+// Report collects the results of a check, printing them as they happen unless the output is JSON
+type Report struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Status  string   `json:"status"`
+	Issues  []*Issue `json:"issues"`
+	Notes   []string `json:"notes,omitempty"`
+	skipped bool
+}
+
+// Issue is a problem found by a check
+type Issue struct {
+	Message string `json:"message"`
+	Fixed   bool   `json:"fixed"`
+	// Fix describes what was done to fix the issue
+	Fix      string `json:"fix,omitempty"`
+	FixError string `json:"fix_error,omitempty"`
+	// Hint is how to fix the issue by hand, when it isn't fixed automatically
+	Hint string `json:"hint,omitempty"`
+}
+
+// NewReport creates the report for a check
+func NewReport(c Check) *Report {
+	return &Report{ID: c.ID, Name: c.Name, Issues: []*Issue{}}
+}
+
+// OK notes that something is fine
+func (r *Report) OK(format string, a ...any) {
+	msg := fmt.Sprintf(format, a...)
+	r.Notes = append(r.Notes, msg)
+	if !ui.IsJSONOutput() {
+		color.HiGreen("%s", msg)
+	}
+}
+
+// Skip notes that the check doesn't apply
+func (r *Report) Skip(format string, a ...any) {
+	r.skipped = true
+	r.OK(format, a...)
+}
+
+// Issue reports a problem
+func (r *Report) Issue(format string, a ...any) *Issue {
+	issue := &Issue{Message: fmt.Sprintf(format, a...)}
+	r.Issues = append(r.Issues, issue)
+	if !ui.IsJSONOutput() {
+		color.HiRed("%s", issue.Message)
+	}
+	return issue
+}
+
+// Hint tells how to fix an issue by hand
+func (r *Report) Hint(issue *Issue, format string, a ...any) {
+	issue.Hint = fmt.Sprintf(format, a...)
+	if !ui.IsJSONOutput() {
+		color.Yellow("  %s", issue.Hint)
+	}
+}
+
+// Fixed reports that an issue was fixed
+func (r *Report) Fixed(issue *Issue, format string, a ...any) {
+	issue.Fixed = true
+	issue.Fix = fmt.Sprintf(format, a...)
+	if !ui.IsJSONOutput() {
+		color.HiGreen("  %s", issue.Fix)
+	}
+}
+
+// FixFailed reports that fixing an issue failed
+func (r *Report) FixFailed(issue *Issue, err error) {
+	issue.FixError = err.Error()
+	if !ui.IsJSONOutput() {
+		color.HiRed("  could not fix: %v", err)
+	}
+}
+
+// Finish sets the status of the check from its issues
+func (r *Report) Finish() {
+	r.Status = StatusOK
+	if r.skipped {
+		r.Status = StatusSkipped
+	}
+	if len(r.Issues) != 0 {
+		r.Status = StatusFixed
+	}
+	for _, issue := range r.Issues {
+		if !issue.Fixed {
+			r.Status = StatusIssues
+		}
+	}
 }
@@ -0,0 +1,250 @@
+package check
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/candrewlee14/webman/config"
+	"github.com/candrewlee14/webman/pkgparse"
+	"github.com/candrewlee14/webman/utils"
+
+	"github.com/matryer/is"
+)
+
+func TestStaleTmp(t *testing.T) {
+	assert := is.New(t)
+
+	utils.Init(t.TempDir())
+	stale := filepath.Join(utils.WebmanTmpDir, "go.tar.gz")
+	fresh := filepath.Join(utils.WebmanTmpDir, "rg.tar.gz")
+	assert.NoErr(os.WriteFile(stale, nil, os.ModePerm)) // Should create stale file
+	assert.NoErr(os.WriteFile(fresh, nil, os.ModePerm)) // Should create fresh file
+	old := time.Now().Add(-2 * utils.StaleTmpAge)
+	assert.NoErr(os.Chtimes(stale, old, old)) // Should age stale file
+
+	report := NewReport(StaleTmp)
+	assert.NoErr(StaleTmp.Func(nil, false, report)) // Should check without fixing
+	report.Finish()
+	assert.Equal(report.Status, StatusIssues) // Stale file should be reported
+	assert.Equal(len(report.Issues), 1)       // Fresh file should be left alone
+
+	report = NewReport(StaleTmp)
+	assert.NoErr(StaleTmp.Func(nil, true, report)) // Should fix
+	report.Finish()
+	assert.Equal(report.Status, StatusFixed) // Stale file should be fixed
+	_, err := os.Stat(stale)
+	assert.True(os.IsNotExist(err)) // Stale file should be removed
+	_, err = os.Stat(fresh)
+	assert.NoErr(err) // Fresh file should be kept
+}
+
+func TestDanglingLinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symlinks needs extra privileges on Windows")
+	}
+	assert := is.New(t)
+
+	utils.Init(t.TempDir())
+	manDir := filepath.Join(utils.WebmanShareDir, "man", "man1")
+	assert.NoErr(os.MkdirAll(manDir, os.ModePerm))                                                                               // Should create share dir
+	assert.NoErr(os.Symlink(filepath.Join(utils.WebmanPkgDir, "rg", "rg-1.0.0", "rg"), filepath.Join(utils.WebmanBinDir, "rg"))) // Should create dangling bin link
+	assert.NoErr(os.Symlink(filepath.Join(utils.WebmanPkgDir, "rg", "rg-1.0.0", "rg.1"), filepath.Join(manDir, "rg.1")))         // Should create dangling man link
+
+	report := NewReport(DanglingLinks)
+	assert.NoErr(DanglingLinks.Func(nil, true, report)) // Should fix
+	report.Finish()
+	assert.Equal(len(report.Issues), 2)      // Bin and share links should be found
+	assert.Equal(report.Status, StatusFixed) // Links should be removed
+	entries, err := os.ReadDir(manDir)
+	assert.NoErr(err)             // Should read share dir
+	assert.Equal(len(entries), 0) // Man link should be gone
+}
+
+var fooRecipe = []byte(`tagline: A test package
+about: Used for testing doctor checks
+base_download_url: https://example.com/[VER]/
+filename_format: foo-[OS]-[ARCH]
+latest_strategy: github-release
+git_user: example
+git_repo: foo
+os_map:
+  linux:
+    name: linux
+    ext: tar.gz
+    bin_path: bin
+arch_map:
+  amd64: x86_64
+`)
+
+// writeFoo writes a recipe for foo in a local repo and installs foo versions with a bin/foo executable
+func writeFoo(t *testing.T, vers ...string) []*config.PkgRepo {
+	assert := is.New(t)
+
+	pkgRepo := &config.PkgRepo{Name: "webman"}
+	assert.NoErr(os.MkdirAll(pkgRepo.PackagePath(), os.ModePerm))                                                // Should create pkgs dir
+	assert.NoErr(os.WriteFile(filepath.Join(pkgRepo.PackagePath(), "foo"+utils.PkgRecipeExt), fooRecipe, 0o644)) // Should write recipe
+	for _, ver := range vers {
+		binDir := filepath.Join(utils.WebmanPkgDir, "foo", utils.CreateStem("foo", ver), "bin")
+		assert.NoErr(os.MkdirAll(binDir, os.ModePerm))                                         // Should create version dir
+		assert.NoErr(os.WriteFile(filepath.Join(binDir, "foo"), []byte("#!/bin/sh\n"), 0o755)) // Should create binary
+	}
+	return []*config.PkgRepo{pkgRepo}
+}
+
+func TestBinPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symlinks needs extra privileges on Windows")
+	}
+	assert := is.New(t)
+
+	home := t.TempDir()
+	utils.Init(home)
+	t.Setenv("HOME", home)
+	t.Setenv("SHELL", "/bin/bash")
+	t.Setenv("PATH", "/nonexistent")
+
+	report := NewReport(BinPath)
+	assert.NoErr(BinPath.Func(nil, false, report)) // Should check without fixing
+	report.Finish()
+	assert.Equal(report.Status, StatusIssues) // Missing bin dir should be reported
+	_, err := os.Stat(filepath.Join(home, ".bashrc"))
+	assert.True(os.IsNotExist(err)) // Profile should not be touched without --fix
+
+	report = NewReport(BinPath)
+	assert.NoErr(BinPath.Func(nil, true, report)) // Should fix
+	report.Finish()
+	assert.Equal(report.Status, StatusFixed) // Missing bin dir should be fixed
+	profile, err := os.ReadFile(filepath.Join(home, ".bashrc"))
+	assert.NoErr(err)                                            // Should write profile
+	assert.True(strings.Contains(string(profile), "shell-init")) // Profile should set up webman
+
+	report = NewReport(BinPath)
+	assert.NoErr(BinPath.Func(nil, true, report)) // Should fix again
+	report.Finish()
+	assert.Equal(report.Status, StatusIssues) // Profile that already sets up webman should not be changed
+
+	otherDir := t.TempDir()
+	assert.NoErr(os.WriteFile(filepath.Join(otherDir, "foo"), nil, 0o755))           // Should create other binary
+	assert.NoErr(os.WriteFile(filepath.Join(utils.WebmanBinDir, "foo"), nil, 0o755)) // Should create webman binary
+	t.Setenv("PATH", otherDir+string(os.PathListSeparator)+utils.WebmanBinDir)
+
+	report = NewReport(BinPath)
+	assert.NoErr(BinPath.Func(nil, true, report)) // Should check shadowed binaries
+	report.Finish()
+	assert.Equal(len(report.Issues), 1)       // Shadowed binary should be reported
+	assert.Equal(report.Status, StatusIssues) // Shadowed binary should not be fixed
+}
+
+func TestUsingMissing(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symlinks needs extra privileges on Windows")
+	}
+	assert := is.New(t)
+
+	utils.Init(t.TempDir())
+	utils.GOOS, utils.GOARCH = "linux", "amd64"
+	defer utils.Init(t.TempDir())
+	pkgRepos := writeFoo(t, "1.0.0", "1.1.0")
+	cfg := &config.Config{PkgRepos: pkgRepos}
+	assert.NoErr(pkgparse.WriteUsing("foo", "foo-2.0.0"))                            // Should use a missing version
+	assert.NoErr(os.MkdirAll(filepath.Join(utils.WebmanPkgDir, "bar"), os.ModePerm)) // Should create package dir
+	assert.NoErr(pkgparse.WriteUsing("bar", "bar-1.0.0"))                            // Should use a missing version of a package with none installed
+
+	report := NewReport(UsingMissing)
+	assert.NoErr(UsingMissing.Func(cfg, false, report)) // Should check without fixing
+	report.Finish()
+	assert.Equal(len(report.Issues), 2)       // Both missing versions should be reported
+	assert.Equal(report.Status, StatusIssues) // Issues should not be fixed
+
+	report = NewReport(UsingMissing)
+	assert.NoErr(UsingMissing.Func(cfg, true, report)) // Should fix
+	report.Finish()
+	assert.Equal(report.Status, StatusFixed) // Missing versions should be fixed
+	using, err := pkgparse.CheckUsing("foo")
+	assert.NoErr(err)                 // Should read using
+	assert.Equal(*using, "foo-1.1.0") // Should switch to the newest installed version
+	target, err := os.Readlink(filepath.Join(utils.WebmanBinDir, "foo"))
+	assert.NoErr(err)                                                                         // Should link binary
+	assert.Equal(target, filepath.Join(utils.WebmanPkgDir, "foo", "foo-1.1.0", "bin", "foo")) // Link should point at the newest version
+	using, err = pkgparse.CheckUsing("bar")
+	assert.NoErr(err)         // Should read using
+	assert.True(using == nil) // Package without versions should no longer be in use
+}
+
+func TestOrphanedPkgs(t *testing.T) {
+	assert := is.New(t)
+
+	utils.Init(t.TempDir())
+	pkgRepos := writeFoo(t, "1.0.0")
+	cfg := &config.Config{PkgRepos: pkgRepos}
+	assert.NoErr(os.MkdirAll(filepath.Join(utils.WebmanPkgDir, "bar", "bar-1.0.0"), os.ModePerm)) // Should install package without recipe
+	assert.NoErr(os.MkdirAll(filepath.Join(utils.WebmanPkgDir, "baz"), os.ModePerm))              // Should leave empty package dir
+
+	report := NewReport(OrphanedPkgs)
+	assert.NoErr(OrphanedPkgs.Func(cfg, true, report)) // Should fix
+	report.Finish()
+	assert.Equal(len(report.Issues), 2)       // Packages without recipes should be reported
+	assert.Equal(report.Status, StatusIssues) // Installed versions should not be removed
+	_, err := os.Stat(filepath.Join(utils.WebmanPkgDir, "bar", "bar-1.0.0"))
+	assert.NoErr(err) // Installed version should be kept
+	_, err = os.Stat(filepath.Join(utils.WebmanPkgDir, "baz"))
+	assert.True(os.IsNotExist(err)) // Leftover package dir should be removed
+	_, err = os.Stat(filepath.Join(utils.WebmanPkgDir, "foo"))
+	assert.NoErr(err) // Package with a recipe should be kept
+}
+
+func TestConfigValid(t *testing.T) {
+	assert := is.New(t)
+
+	utils.Init(t.TempDir())
+	assert.NoErr(os.WriteFile(utils.WebmanConfig, []byte("pkg_repos: [\n"), os.ModePerm)) // Should write invalid config
+
+	report := NewReport(ConfigValid)
+	assert.NoErr(ConfigValid.Func(nil, false, report)) // Should check without fixing
+	report.Finish()
+	assert.Equal(report.Status, StatusIssues) // Invalid config should be reported
+
+	report = NewReport(ConfigValid)
+	assert.NoErr(ConfigValid.Func(nil, true, report)) // Should fix
+	report.Finish()
+	assert.Equal(report.Status, StatusFixed) // Invalid config should be replaced
+	backup, err := os.ReadFile(utils.WebmanConfig + ".invalid")
+	assert.NoErr(err)                              // Invalid config should be kept aside
+	assert.Equal(string(backup), "pkg_repos: [\n") // Backup should be the invalid config
+	_, err = config.Load()
+	assert.NoErr(err) // Default config should load
+}
+
+func TestUnreachableRepos(t *testing.T) {
+	assert := is.New(t)
+
+	utils.Init(t.TempDir())
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("ref") != "main" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	webman := &config.PkgRepo{Name: "webman", Type: config.PkgRepoTypeGitea, GiteaURL: srv.URL, User: "u", Repo: "r", Branch: "main"}
+	gone := &config.PkgRepo{Name: "gone", Type: config.PkgRepoTypeGitea, GiteaURL: srv.URL, User: "u", Repo: "r", Branch: "old"}
+	cfg := &config.Config{PkgRepos: []*config.PkgRepo{webman, gone}}
+
+	report := NewReport(UnreachableRepos)
+	assert.NoErr(UnreachableRepos.Func(cfg, false, report)) // Should check without fixing
+	report.Finish()
+	assert.Equal(len(report.Issues), 1)                           // Repo without its revision should be reported
+	assert.True(strings.Contains(report.Issues[0].Hint, "--fix")) // Hint should mention --fix
+
+	report = NewReport(UnreachableRepos)
+	assert.NoErr(UnreachableRepos.Func(cfg, true, report)) // Should try to fix
+	report.Finish()
+	assert.Equal(report.Status, StatusIssues)    // Repo should not be removed without confirmation
+	assert.True(report.Issues[0].FixError != "") // Missing confirmation should be reported
+	assert.Equal(len(cfg.PkgRepos), 2)           // Config should be unchanged
+}
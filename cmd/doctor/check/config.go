@@ -0,0 +1,38 @@
+package check
+
+import (
+	"os"
+
+	"github.com/candrewlee14/webman/config"
+	"github.com/candrewlee14/webman/utils"
+)
+
+// ConfigValid checks that the config can be loaded
+var ConfigValid = Check{
+	Name: "Config",
+	ID:   "config",
+	Func: func(cfg *config.Config, fix bool, r *Report) error {
+		_, err := config.Load()
+		if err == nil {
+			r.OK("config is valid")
+			return nil
+		}
+		issue := r.Issue("invalid config %q: %v", utils.WebmanConfig, err)
+		if !fix {
+			r.Hint(issue, "correct the config, or run with --fix to replace it with the default config")
+			return nil
+		}
+		backup := utils.WebmanConfig + ".invalid"
+		if err := os.Rename(utils.WebmanConfig, backup); err != nil {
+			r.FixFailed(issue, err)
+			return nil
+		}
+		// loading writes the default config when there is none
+		if _, err := config.Load(); err != nil {
+			r.FixFailed(issue, err)
+			return nil
+		}
+		r.Fixed(issue, "moved the invalid config to %q and wrote the default config", backup)
+		return nil
+	},
+}
@@ -0,0 +1,58 @@
+package check
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/candrewlee14/webman/config"
+	"github.com/candrewlee14/webman/link"
+	"github.com/candrewlee14/webman/utils"
+)
+
+// DanglingLinks checks for links in the bin and share directories whose packages were removed
+var DanglingLinks = Check{
+	Name: "Dangling Links",
+	ID:   "dangling_links",
+	Func: func(cfg *config.Config, fix bool, r *Report) error {
+		dirs := []string{utils.WebmanBinDir}
+		err := filepath.WalkDir(utils.WebmanShareDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				dirs = append(dirs, path)
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		var dangling []string
+		for _, dir := range dirs {
+			links, err := link.DanglingLinks(dir)
+			if err != nil {
+				return err
+			}
+			dangling = append(dangling, links...)
+		}
+		if len(dangling) == 0 {
+			r.OK("no dangling links found")
+			return nil
+		}
+		for _, linkPath := range dangling {
+			issue := r.Issue("dangling link %q", linkPath)
+			if !fix {
+				r.Hint(issue, "run with --fix to remove it")
+				continue
+			}
+			if err := os.Remove(linkPath); err != nil {
+				r.FixFailed(issue, err)
+				continue
+			}
+			r.Fixed(issue, "removed the link")
+		}
+		return nil
+	},
+}
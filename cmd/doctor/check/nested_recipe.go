@@ -2,41 +2,52 @@ package check
 
 import (
 	"errors"
+	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/candrewlee14/webman/config"
 	"github.com/candrewlee14/webman/utils"
-
-	"github.com/fatih/color"
 )
 
 // NestedRecipe v0.8.0 -> v0.9.0 introduced configurable repos, which moved the recipes dir one level deeper
 var NestedRecipe = Check{
 	Name: "Nested Recipes",
-	Func: func(cfg *config.Config, fix bool) error {
+	ID:   "nested_recipes",
+	Func: func(cfg *config.Config, fix bool, r *Report) error {
+		if utils.RecipeDirFlag != "" {
+			r.Skip("using local recipes; skipping")
+			return nil
+		}
 		_, pkgsErr := os.Stat(filepath.Join(utils.WebmanRecipeDir, "pkgs"))
 		_, groupsErr := os.Stat(filepath.Join(utils.WebmanRecipeDir, "groups"))
 		if errors.Is(pkgsErr, fs.ErrNotExist) && errors.Is(groupsErr, fs.ErrNotExist) {
-			color.HiGreen("no un-nested recipes detected")
+			r.OK("no un-nested recipes detected")
 			return nil
 		}
 
+		issue := r.Issue("detected un-nested recipes in %q", utils.WebmanRecipeDir)
 		if !fix {
-			color.HiRed("detected un-nested recipes, please clean up %q", utils.WebmanRecipeDir)
+			r.Hint(issue, "clean up %q, or run with --fix", utils.WebmanRecipeDir)
 			return nil
 		}
 
 		// As of https://github.com/candrewlee14/webman-pkgs/tree/9eda7908a9f25398c1c693e4ec7dc7a727eddf71
 		remove := []string{".github", "groups", "pkgs", ".mega-linter.yml", ".pre-commit-config.yaml", "LICENSE", "README.md", "full-bintest.sh", "refresh.yaml"}
-		for _, r := range remove {
-			if err := os.RemoveAll(filepath.Join(utils.WebmanRecipeDir, r)); err != nil {
-				color.HiRed("could not remove %q: %v", r, err)
+		var errs []string
+		for _, rm := range remove {
+			if err := os.RemoveAll(filepath.Join(utils.WebmanRecipeDir, rm)); err != nil {
+				errs = append(errs, fmt.Sprintf("could not remove %q: %v", rm, err))
 			}
 		}
+		if len(errs) != 0 {
+			r.FixFailed(issue, errors.New(strings.Join(errs, "; ")))
+			return nil
+		}
 
-		color.HiGreen("successfully cleaned un-nested recipes")
+		r.Fixed(issue, "successfully cleaned un-nested recipes")
 		return nil
 	},
 }
@@ -0,0 +1,53 @@
+package check
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/candrewlee14/webman/config"
+	"github.com/candrewlee14/webman/pkgparse"
+	"github.com/candrewlee14/webman/utils"
+)
+
+// OrphanedPkgs checks for installed packages that have no recipe in any repo,
+// which can't be upgraded or reinstalled
+var OrphanedPkgs = Check{
+	Name:        "Orphaned Packages",
+	ID:          "orphaned_packages",
+	NeedsConfig: true,
+	Func: func(cfg *config.Config, fix bool, r *Report) error {
+		orphaned := 0
+		for _, pkg := range utils.InstalledPackages() {
+			found, err := pkgparse.FindPkgRepos(cfg.PkgRepos, pkg)
+			if err != nil {
+				return err
+			}
+			if len(found) != 0 {
+				continue
+			}
+			orphaned++
+			stems, _ := utils.InstalledPkgVerStems(pkg)
+			if len(stems) != 0 {
+				issue := r.Issue("%s has no recipe in any repo", pkg)
+				// installed versions still work, so they are only removed on request
+				r.Hint(issue, `add a repo that provides %s with "webman config add", or remove it with "webman remove %s --all"`, pkg, pkg)
+				continue
+			}
+			pkgDir := filepath.Join(utils.WebmanPkgDir, pkg)
+			issue := r.Issue("%q is left over from %s, which has no recipe or installed versions", pkgDir, pkg)
+			if !fix {
+				r.Hint(issue, "run with --fix to remove it")
+				continue
+			}
+			if err := os.RemoveAll(pkgDir); err != nil {
+				r.FixFailed(issue, err)
+				continue
+			}
+			r.Fixed(issue, "removed the directory")
+		}
+		if orphaned == 0 {
+			r.OK("all installed packages have a recipe")
+		}
+		return nil
+	},
+}
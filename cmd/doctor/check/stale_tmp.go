@@ -0,0 +1,50 @@
+package check
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/candrewlee14/webman/config"
+	"github.com/candrewlee14/webman/utils"
+)
+
+// StaleTmp checks for temporary files left over by interrupted installs and refreshes
+var StaleTmp = Check{
+	Name: "Stale Temporary Files",
+	ID:   "stale_tmp",
+	Func: func(cfg *config.Config, fix bool, r *Report) error {
+		entries, err := os.ReadDir(utils.WebmanTmpDir)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		stale := 0
+		for _, entry := range entries {
+			fi, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			if time.Since(fi.ModTime()) < utils.StaleTmpAge {
+				continue
+			}
+			stale++
+			tmpPath := filepath.Join(utils.WebmanTmpDir, entry.Name())
+			issue := r.Issue("stale temporary file %q", tmpPath)
+			if !fix {
+				r.Hint(issue, `run with --fix or "webman gc" to remove it`)
+				continue
+			}
+			if err := os.RemoveAll(tmpPath); err != nil {
+				r.FixFailed(issue, err)
+				continue
+			}
+			r.Fixed(issue, "removed the file")
+		}
+		if stale == 0 {
+			r.OK("no stale temporary files found")
+		}
+		return nil
+	},
+}
@@ -0,0 +1,77 @@
+package check
+
+import (
+	"fmt"
+
+	"github.com/candrewlee14/webman/config"
+	"github.com/candrewlee14/webman/ui"
+	"github.com/candrewlee14/webman/utils"
+
+	"github.com/AlecAivazis/survey/v2"
+)
+
+// UnreachableRepos checks that the recipes of every configured repo can be fetched.
+// A repo whose revision no longer exists is removed from the config after confirmation,
+// keeping the packages installed from it; network problems are only reported.
+var UnreachableRepos = Check{
+	Name:        "Unreachable Repos",
+	ID:          "unreachable_repos",
+	NeedsConfig: true,
+	Func: func(cfg *config.Config, fix bool, r *Report) error {
+		if utils.RecipeDirFlag != "" {
+			r.Skip("using local recipes; skipping")
+			return nil
+		}
+		// fixes remove repos from the config, so a copy is checked
+		pkgRepos := append([]*config.PkgRepo(nil), cfg.PkgRepos...)
+		for _, pkgRepo := range pkgRepos {
+			ok, err := pkgRepo.Validate()
+			if err != nil {
+				issue := r.Issue("repo %s is unreachable: %v", pkgRepo.Name, err)
+				// network problems can't be fixed here
+				r.Hint(issue, "check your network connection and the repo's settings in %q", utils.WebmanConfig)
+				continue
+			}
+			if ok {
+				r.OK("repo %s is reachable", pkgRepo.Name)
+				continue
+			}
+			issue := r.Issue("repo %s does not exist at revision %s", pkgRepo.Name, pkgRepo.Revision())
+			if !fix {
+				r.Hint(issue, `correct the repo's settings in %q, run with --fix to remove it from the config, or run "webman config remove %s" to also uninstall its packages`,
+					utils.WebmanConfig, pkgRepo.Name)
+				continue
+			}
+			if err := removeRepo(cfg, pkgRepo.Name); err != nil {
+				r.FixFailed(issue, err)
+				continue
+			}
+			r.Fixed(issue, "removed the repo from the config; packages installed from it were kept")
+		}
+		return nil
+	},
+}
+
+// removeRepo removes a repo from the config after confirmation
+func removeRepo(cfg *config.Config, name string) error {
+	if len(cfg.PkgRepos) == 1 {
+		return fmt.Errorf("cannot remove the only package repository")
+	}
+	if !ui.AreInteractivePromptsEnabled() {
+		return ui.Errorf(ui.CodePromptUnavailable, "removing repo %s needs confirmation in an interactive terminal", name)
+	}
+	remove := false
+	q := &survey.Confirm{
+		Message: fmt.Sprintf("Remove repo %q from the config? Packages installed from it are kept", name),
+	}
+	if err := survey.AskOne(q, &remove); err != nil {
+		return err
+	}
+	if !remove {
+		return fmt.Errorf("declined removing repo %s", name)
+	}
+	if err := cfg.RemoveRepo(name); err != nil {
+		return err
+	}
+	return cfg.Save()
+}
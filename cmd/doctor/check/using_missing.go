@@ -0,0 +1,83 @@
+package check
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/candrewlee14/webman/config"
+	"github.com/candrewlee14/webman/link"
+	"github.com/candrewlee14/webman/pkgparse"
+	"github.com/candrewlee14/webman/utils"
+)
+
+// UsingMissing checks for packages using a version whose directory no longer exists
+var UsingMissing = Check{
+	Name:        "Missing Versions In Use",
+	ID:          "using_missing",
+	NeedsConfig: true,
+	Func: func(cfg *config.Config, fix bool, r *Report) error {
+		missing := 0
+		for _, pkg := range utils.InstalledPackages() {
+			using, err := pkgparse.CheckUsing(pkg)
+			if err != nil {
+				return err
+			}
+			if using == nil {
+				continue
+			}
+			if _, err := os.Stat(filepath.Join(utils.WebmanPkgDir, pkg, *using)); err == nil {
+				continue
+			}
+			missing++
+			issue := r.Issue("%s is using %s, which is not installed", pkg, *using)
+			if !fix {
+				r.Hint(issue, `run with --fix to switch to the newest installed version, or "webman switch %s"`, pkg)
+				continue
+			}
+			stems, _ := utils.InstalledPkgVerStems(pkg)
+			if len(stems) == 0 {
+				if err := pkgparse.RemoveUsing(pkg); err != nil {
+					r.FixFailed(issue, err)
+					continue
+				}
+				r.Fixed(issue, "no versions are installed, so %s is no longer in use", pkg)
+				continue
+			}
+			newest := stems[len(stems)-1]
+			if err := switchTo(cfg, pkg, newest); err != nil {
+				r.FixFailed(issue, err)
+				continue
+			}
+			r.Fixed(issue, "switched to %s", newest)
+		}
+		if missing == 0 {
+			r.OK("all versions in use are installed")
+		}
+		return nil
+	},
+}
+
+// switchTo links an installed package version and makes it the one in use
+func switchTo(cfg *config.Config, pkg string, pkgVerStem string) error {
+	pkgConf, err := pkgparse.ParseInstalledPkgConfig(cfg.PkgRepos, pkg, pkgVerStem)
+	if err != nil {
+		return err
+	}
+	binPaths, err := pkgConf.GetMyBinPaths()
+	if err != nil {
+		return err
+	}
+	renames, err := pkgConf.GetRenames()
+	if err != nil {
+		return err
+	}
+	_, ver := utils.ParseStem(pkgVerStem)
+	if _, err := link.CreateLinks(pkg, ver, binPaths, renames); err != nil {
+		return err
+	}
+	if err := pkgConf.WriteUsingEnv(pkgVerStem); err != nil {
+		return err
+	}
+	_, err = link.CreateShareLinks(pkgConf, pkgVerStem)
+	return err
+}
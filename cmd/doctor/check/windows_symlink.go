@@ -5,22 +5,21 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/candrewlee14/webman/config"
 	"github.com/candrewlee14/webman/link"
 	"github.com/candrewlee14/webman/pkgparse"
 	"github.com/candrewlee14/webman/utils"
-
-	"github.com/fatih/color"
 )
 
 // Windows switched from batch files to symlinks
 var WindowsSymlink = Check{
-	Name: "Windows Symlink",
-	Func: func(cfg *config.Config, fix bool) error {
+	Name:        "Windows Symlink",
+	ID:          "windows_symlink",
+	NeedsConfig: true,
+	Func: func(cfg *config.Config, fix bool, r *Report) error {
 		if utils.GOOS != "windows" {
-			color.HiGreen("current OS isn't windows; skipping")
+			r.Skip("current OS isn't windows; skipping")
 			return nil
 		}
 
@@ -30,46 +29,50 @@ var WindowsSymlink = Check{
 		}
 
 		for _, i := range installed {
-			pkgConfig, err := pkgparse.ParsePkgConfigLocal(cfg.PkgRepos, i.Name())
-			if err != nil {
-				return err
-			}
-
-			binPaths, err := pkgConfig.GetMyBinPaths()
-			if err != nil {
-				return err
-			}
-			renames, err := pkgConfig.GetRenames()
-			if err != nil {
-				return err
-			}
 			using, err := pkgparse.CheckUsing(i.Name())
 			if err != nil {
 				return err
 			}
-			parts := strings.Split(*using, "-")
-			ver := parts[len(parts)-1]
+			if using == nil {
+				continue
+			}
+			_, ver := utils.ParseStem(*using)
 
 			_, err = os.Lstat(filepath.Join(utils.WebmanBinDir, i.Name()+".exe"))
 			if err == nil {
 				continue
 			}
-			if err != nil {
-				if !errors.Is(err, fs.ErrNotExist) {
-					color.HiRed("could not lstat %q: %v", i.Name(), err)
-					continue
-				}
+			if !errors.Is(err, fs.ErrNotExist) {
+				r.Issue("could not lstat %q: %v", i.Name(), err)
+				continue
 			}
 
+			issue := r.Issue("no symlink(s) found for %q", i.Name())
 			if !fix {
-				color.HiRed("no symlink(s) found for %q", i.Name())
+				r.Hint(issue, "run with --fix to create them")
 				continue
 			}
 
-			color.HiGreen("creating symlink(s) for %s", i.Name())
+			pkgConfig, err := pkgparse.ParseInstalledPkgConfig(cfg.PkgRepos, i.Name(), *using)
+			if err != nil {
+				r.FixFailed(issue, err)
+				continue
+			}
+			binPaths, err := pkgConfig.GetMyBinPaths()
+			if err != nil {
+				r.FixFailed(issue, err)
+				continue
+			}
+			renames, err := pkgConfig.GetRenames()
+			if err != nil {
+				r.FixFailed(issue, err)
+				continue
+			}
 			if _, err := link.CreateLinks(i.Name(), ver, binPaths, renames); err != nil {
-				color.HiRed("could not create symlink(s) for %q: %v", i.Name(), err)
+				r.FixFailed(issue, err)
+				continue
 			}
+			r.Fixed(issue, "created symlink(s) for %s", i.Name())
 		}
 
 		bats, err := filepath.Glob(filepath.Join(utils.WebmanBinDir, "*.bat"))
@@ -78,20 +81,21 @@ var WindowsSymlink = Check{
 		}
 
 		if len(bats) == 0 {
-			color.HiGreen("no batch files found")
-			return nil
-		}
-
-		if !fix {
-			color.HiRed("found %d batch file(s)", len(bats))
+			r.OK("no batch files found")
 			return nil
 		}
 
-		color.HiGreen("removing batch file(s)")
 		for _, bat := range bats {
+			issue := r.Issue("found batch file %q", bat)
+			if !fix {
+				r.Hint(issue, "run with --fix to remove it")
+				continue
+			}
 			if err := os.Remove(bat); err != nil {
-				color.HiRed("could not remove batch file %q: %v", bat, err)
+				r.FixFailed(issue, err)
+				continue
 			}
+			r.Fixed(issue, "removed batch file")
 		}
 
 		return nil
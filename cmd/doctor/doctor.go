@@ -3,6 +3,7 @@ package doctor
 import (
 	"github.com/candrewlee14/webman/cmd/doctor/check"
 	"github.com/candrewlee14/webman/config"
+	"github.com/candrewlee14/webman/ui"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
@@ -11,8 +12,15 @@ import (
 var (
 	fix    bool
 	checks = []check.Check{
+		check.ConfigValid,
 		check.NestedRecipe,
 		check.WindowsSymlink,
+		check.BinPath,
+		check.DanglingLinks,
+		check.UsingMissing,
+		check.OrphanedPkgs,
+		check.StaleTmp,
+		check.UnreachableRepos,
 	}
 )
 
@@ -22,20 +30,63 @@ var DoctorCmd = &cobra.Command{
 	Long: `
 
 The "doctor" subcommand checks for potential issues. webman can attempt to automatically fix issues using --fix
+
+It checks the config, the bin directory being on the PATH without being shadowed, dangling links,
+versions in use that are missing, packages without recipes, stale temporary files and unreachable repos.
+Some issues are only reported, since fixing them could break other tools or installed packages:
+binaries shadowed by other PATH entries, repos unreachable over the network,
+and packages without recipes that still have installed versions.
+With --fix, a repo whose revision no longer exists is removed from the config after confirmation,
+keeping the packages installed from it.
+Use --output json for a report of every check. The exit status is non-zero if issues remain.
 `,
+	Example: `webman doctor
+webman doctor --fix
+webman doctor --output json`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg, err := config.Load()
-		if err != nil {
-			return err
+		var reports []*check.Report
+		var cfg *config.Config
+		var cfgErr error
+		cfgLoaded := false
+		for _, c := range checks {
+			if !ui.IsJSONOutput() {
+				color.HiBlue("== %s", c.Name)
+			}
+			report := check.NewReport(c)
+			if c.NeedsConfig && !cfgLoaded {
+				cfg, cfgErr = config.Load()
+				cfgLoaded = true
+			}
+			if c.NeedsConfig && cfgErr != nil {
+				report.Skip("unable to load the config (%v); skipping", cfgErr)
+			} else if err := c.Func(cfg, fix, report); err != nil {
+				return err
+			}
+			report.Finish()
+			reports = append(reports, report)
+			// the config check may have fixed the config, so the checks after it load it again
+			if c.ID == check.ConfigValid.ID {
+				cfgLoaded = false
+			}
 		}
 
-		for _, check := range checks {
-			color.HiBlue("== %s", check.Name)
-			if err := check.Func(cfg, fix); err != nil {
+		healthy := true
+		for _, report := range reports {
+			if report.Status == check.StatusIssues {
+				healthy = false
+			}
+		}
+		if ui.IsJSONOutput() {
+			if err := ui.PrintJSON(struct {
+				Healthy bool            `json:"healthy"`
+				Checks  []*check.Report `json:"checks"`
+			}{healthy, reports}); err != nil {
 				return err
 			}
 		}
-
+		if !healthy {
+			return ui.Errorf(ui.CodeUnhealthy, "found issues that were not fixed")
+		}
 		return nil
 	},
 }
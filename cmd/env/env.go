@@ -0,0 +1,142 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/candrewlee14/webman/pkgparse"
+	"github.com/candrewlee14/webman/ui"
+	"github.com/candrewlee14/webman/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	shellFlag   string
+	projectFlag bool
+)
+
+// Shells are the shells that env can print exports for
+var Shells = []string{"bash", "zsh", "fish", "powershell"}
+
+// EnvCmd represents the env command
+var EnvCmd = &cobra.Command{
+	Use:   "env",
+	Short: "print environment variables of the packages in use",
+	Long: `
+The "env" subcommand prints shell commands that export the environment variables
+set by the recipes of the package versions in use, like GOROOT for go.
+Switching versions updates the variables, so evaluate the output again after "webman switch".
+The shell is detected from $SHELL unless --shell is given.
+
+With --project, the package versions listed in the nearest ` + utils.ProjectFileName + ` project file are put first
+on the PATH along with their variables. The hook installed by "webman shell-init" does this on every directory change.`,
+	Example: `eval "$(webman env)"
+webman env --shell fish | source
+webman env --shell powershell | Out-String | Invoke-Expression`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			return cmd.Help()
+		}
+		shell := shellFlag
+		if shell == "" {
+			shell = DetectShell()
+		}
+		vars, err := pkgparse.ActiveEnv()
+		if err != nil {
+			return err
+		}
+		var unset []string
+		if projectFlag {
+			if vars, unset, err = projectEnv(vars); err != nil {
+				return err
+			}
+		}
+		if ui.IsJSONOutput() {
+			return ui.PrintJSON(struct {
+				Env   []pkgparse.EnvVar `json:"env"`
+				Unset []string          `json:"unset,omitempty"`
+			}{vars, unset})
+		}
+		exports, err := FormatExports(shell, vars, unset)
+		if err != nil {
+			return err
+		}
+		fmt.Print(exports)
+		return nil
+	},
+}
+
+func init() {
+	EnvCmd.Flags().StringVarP(&shellFlag, "shell", "s", "", "shell to print exports for: "+strings.Join(Shells, ", "))
+	EnvCmd.Flags().BoolVar(&projectFlag, "project", false, "also apply the "+utils.ProjectFileName+" project file of the working directory")
+}
+
+// DetectShell guesses the current shell from $SHELL, defaulting to powershell on Windows and bash elsewhere
+func DetectShell() string {
+	if shell := filepath.Base(os.Getenv("SHELL")); shell == "zsh" || shell == "fish" {
+		return shell
+	}
+	if utils.GOOS == "windows" {
+		return "powershell"
+	}
+	return "bash"
+}
+
+// ApplyHint is the command that applies the output of env to the current shell
+func ApplyHint() string {
+	switch DetectShell() {
+	case "fish":
+		return "webman env | source"
+	case "powershell":
+		return "webman env | Out-String | Invoke-Expression"
+	default:
+		return `eval "$(webman env)"`
+	}
+}
+
+// FormatExports formats environment variables as export commands for a shell, followed by commands unsetting variables
+func FormatExports(shell string, vars []pkgparse.EnvVar, unset []string) (string, error) {
+	var format func(v pkgparse.EnvVar) string
+	var formatUnset func(name string) string
+	switch shell {
+	case "bash", "zsh", "sh":
+		format = func(v pkgparse.EnvVar) string {
+			return fmt.Sprintf("export %s='%s'\n", v.Name, strings.ReplaceAll(v.Value, "'", `'\''`))
+		}
+		formatUnset = func(name string) string {
+			return fmt.Sprintf("unset %s\n", name)
+		}
+	case "fish":
+		format = func(v pkgparse.EnvVar) string {
+			value := strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(v.Value)
+			if v.Name == "PATH" {
+				// fish keeps PATH as a list
+				value = strings.ReplaceAll(value, string(os.PathListSeparator), "' '")
+			}
+			return fmt.Sprintf("set -gx %s '%s'\n", v.Name, value)
+		}
+		formatUnset = func(name string) string {
+			return fmt.Sprintf("set -e %s\n", name)
+		}
+	case "powershell", "pwsh":
+		format = func(v pkgparse.EnvVar) string {
+			return fmt.Sprintf("$env:%s = '%s'\n", v.Name, strings.ReplaceAll(v.Value, "'", "''"))
+		}
+		formatUnset = func(name string) string {
+			return fmt.Sprintf("Remove-Item Env:%s -ErrorAction SilentlyContinue\n", name)
+		}
+	default:
+		return "", ui.Errorf(ui.CodeInvalidArgs, "unsupported shell %q, expected one of %s", shell, strings.Join(Shells, ", "))
+	}
+	var sb strings.Builder
+	for _, v := range vars {
+		sb.WriteString(format(v))
+	}
+	for _, name := range unset {
+		sb.WriteString(formatUnset(name))
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,37 @@
+package env
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/candrewlee14/webman/pkgparse"
+
+	"github.com/matryer/is"
+)
+
+func TestFormatExports(t *testing.T) {
+	assert := is.New(t)
+
+	vars := []pkgparse.EnvVar{{Name: "GOROOT", Value: "/home/me/it's go"}}
+	exports, err := FormatExports("bash", vars, []string{"OLD"})
+	assert.NoErr(err)                                                          // Should format bash exports
+	assert.Equal(exports, "export GOROOT='/home/me/it'\\''s go'\nunset OLD\n") // Quotes should be escaped
+	exports, err = FormatExports("fish", vars, nil)
+	assert.NoErr(err)                                              // Should format fish exports
+	assert.Equal(exports, "set -gx GOROOT '/home/me/it\\'s go'\n") // Quotes should be escaped
+	exports, err = FormatExports("powershell", vars, nil)
+	assert.NoErr(err)                                            // Should format powershell exports
+	assert.Equal(exports, "$env:GOROOT = '/home/me/it''s go'\n") // Quotes should be escaped
+	_, err = FormatExports("tcsh", vars, nil)
+	assert.True(err != nil) // Unsupported shells should fail
+}
+
+func TestWithoutPrevious(t *testing.T) {
+	assert := is.New(t)
+
+	sep := string(os.PathListSeparator)
+	path := strings.Join([]string{"/proj/go/bin", "/usr/bin", "/proj/go/bin"}, sep)
+	assert.Equal(withoutPrevious(path, "/proj/go/bin"), []string{"/usr/bin", "/proj/go/bin"})     // Only added entries should be removed
+	assert.Equal(withoutPrevious(path, ""), []string{"/proj/go/bin", "/usr/bin", "/proj/go/bin"}) // Nothing should be removed without a previous project
+}
@@ -0,0 +1,161 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/candrewlee14/webman/config"
+	"github.com/candrewlee14/webman/link"
+	"github.com/candrewlee14/webman/pkgparse"
+	"github.com/candrewlee14/webman/utils"
+
+	"github.com/fatih/color"
+)
+
+const (
+	// projectPathVar remembers the PATH entries added for the current project, so they can be taken out again
+	projectPathVar = "WEBMAN_PROJECT_PATH"
+	// projectEnvVar remembers the names of the variables set for the current project
+	projectEnvVar = "WEBMAN_PROJECT_ENV"
+)
+
+// projectEnv applies the project file of the working directory on top of the active environment variables.
+// The bin directories of the project's package versions are put first on the PATH,
+// replacing those of a previous project, and variables only set by a previous project are unset.
+func projectEnv(vars []pkgparse.EnvVar) ([]pkgparse.EnvVar, []string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, nil, err
+	}
+	projectPath, err := pkgparse.FindProjectFile(wd)
+	if err != nil {
+		return nil, nil, err
+	}
+	var binDirs []string
+	projectVars := make(map[string]pkgparse.EnvVar)
+	if projectPath != "" {
+		project, err := pkgparse.ParseProjectFile(projectPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		cfg, err := config.Load()
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, arg := range project.Packages {
+			_, pkg, ver, _ := utils.ParsePkgVer(arg)
+			pkgVerStem := pkgparse.NewestInstalledVersion(pkg, ver)
+			if pkgVerStem == "" {
+				fmt.Fprintln(os.Stderr, color.YellowString("webman: %s from %s is not installed; run \"webman add %s\"", arg, projectPath, arg))
+				continue
+			}
+			pkgConf, err := pkgparse.ParseInstalledPkgConfig(cfg.PkgRepos, pkg, pkgVerStem)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, color.YellowString("webman: %v", err))
+				continue
+			}
+			dirs, err := versionBinDirs(pkgConf, pkgVerStem)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, color.YellowString("webman: %s: %v", pkgVerStem, err))
+				continue
+			}
+			binDirs = append(binDirs, dirs...)
+			for name, value := range pkgConf.EnvVars(pkgVerStem) {
+				projectVars[name] = pkgparse.EnvVar{Name: name, Value: value, Pkg: pkg}
+			}
+		}
+	}
+
+	for i, v := range vars {
+		if projectVar, ok := projectVars[v.Name]; ok {
+			vars[i] = projectVar
+			delete(projectVars, v.Name)
+		}
+	}
+	var names []string
+	for name := range projectVars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		vars = append(vars, projectVars[name])
+	}
+
+	set := make(map[string]bool)
+	for _, v := range vars {
+		set[v.Name] = true
+	}
+	var unset []string
+	for _, name := range filepath.SplitList(os.Getenv(projectEnvVar)) {
+		if !set[name] {
+			unset = append(unset, name)
+		}
+	}
+
+	sep := string(os.PathListSeparator)
+	previous := os.Getenv(projectPathVar)
+	if len(binDirs) != 0 || previous != "" {
+		vars = append(vars, pkgparse.EnvVar{
+			Name:  "PATH",
+			Value: strings.Join(append(binDirs, withoutPrevious(os.Getenv("PATH"), previous)...), sep),
+		})
+	}
+	if len(binDirs) != 0 {
+		vars = append(vars, pkgparse.EnvVar{Name: projectPathVar, Value: strings.Join(binDirs, sep)})
+	} else if previous != "" {
+		unset = append(unset, projectPathVar)
+	}
+	if len(names) != 0 {
+		vars = append(vars, pkgparse.EnvVar{Name: projectEnvVar, Value: strings.Join(names, sep)})
+	} else if os.Getenv(projectEnvVar) != "" {
+		unset = append(unset, projectEnvVar)
+	}
+	return vars, unset, nil
+}
+
+// withoutPrevious removes the entries added for a previous project from a PATH
+func withoutPrevious(path string, previous string) []string {
+	remove := make(map[string]int)
+	for _, dir := range filepath.SplitList(previous) {
+		remove[dir]++
+	}
+	var dirs []string
+	for _, dir := range filepath.SplitList(path) {
+		if remove[dir] > 0 {
+			remove[dir]--
+			continue
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// versionBinDirs returns the directories holding the binaries of an installed package version
+func versionBinDirs(pkgConf *pkgparse.PkgConfig, pkgVerStem string) ([]string, error) {
+	relBinPaths, err := pkgConf.GetMyBinPaths()
+	if err != nil {
+		return nil, err
+	}
+	renames, err := pkgConf.GetRenames()
+	if err != nil {
+		return nil, err
+	}
+	_, ver := utils.ParseStem(pkgVerStem)
+	binPaths, _, err := link.GetBinPathsAndLinkPaths(pkgConf.Title, ver, relBinPaths, renames)
+	if err != nil {
+		return nil, err
+	}
+	var dirs []string
+	seen := make(map[string]bool)
+	for _, binPath := range binPaths {
+		dir := filepath.Dir(binPath)
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs, nil
+}
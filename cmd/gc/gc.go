@@ -0,0 +1,221 @@
+package gc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/candrewlee14/webman/cmd/remove"
+	"github.com/candrewlee14/webman/config"
+	"github.com/candrewlee14/webman/link"
+	"github.com/candrewlee14/webman/pkgparse"
+	"github.com/candrewlee14/webman/ui"
+	"github.com/candrewlee14/webman/utils"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	keepFlag   int
+	dryRunFlag bool
+	yesFlag    bool
+)
+
+// GcCmd represents the gc command
+var GcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "remove old package versions and leftover files",
+	Long: `
+The "gc" subcommand removes every installed package version that is not in use,
+except for the given number of most recent versions of each package, after asking for confirmation.
+Packages without a version in use are left alone.
+It also cleans up temporary files left over by interrupted installs and refreshes,
+and dangling links in the webman bin directory.`,
+	Example: `webman gc
+webman gc --keep 1
+webman gc --dry-run
+webman gc --yes`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			return cmd.Help()
+		}
+		if keepFlag < 0 {
+			return ui.Errorf(ui.CodeInvalidArgs, "--keep must not be negative")
+		}
+		verb := "Removed"
+		if dryRunFlag {
+			verb = "Would remove"
+		}
+		report := gcReport{Versions: []string{}, TmpFiles: []string{}, Links: []string{}}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		var unused []unusedVersion
+		for _, pkg := range utils.InstalledPackages() {
+			stems, err := utils.InstalledPkgVerStems(pkg)
+			if err != nil {
+				return err
+			}
+			using, err := pkgparse.CheckUsing(pkg)
+			if err != nil {
+				return err
+			}
+			// without an active version, there's no telling which version is still wanted
+			if using == nil || !containsStem(stems, *using) {
+				if len(stems) != 0 {
+					color.HiBlack("Skipping %s, since no installed version is in use", pkg)
+				}
+				continue
+			}
+			var inactive []string
+			for _, stem := range stems {
+				if stem != *using {
+					inactive = append(inactive, stem)
+				}
+			}
+			if len(inactive) <= keepFlag {
+				continue
+			}
+			// stems are sorted oldest to newest, so keep the end of the list
+			for _, stem := range inactive[:len(inactive)-keepFlag] {
+				size, err := utils.DirSize(filepath.Join(utils.WebmanPkgDir, pkg, stem))
+				if err != nil {
+					return err
+				}
+				unused = append(unused, unusedVersion{pkg: pkg, stem: stem, using: using, size: size})
+			}
+		}
+		removeVersions := len(unused) != 0 && !dryRunFlag
+		if removeVersions && !yesFlag {
+			if !ui.AreInteractivePromptsEnabled() {
+				return ui.Errorf(ui.CodePromptUnavailable, "refusing to remove %d unused version(s) without confirmation; pass --yes", len(unused))
+			}
+			stems := make([]string, len(unused))
+			for i, version := range unused {
+				stems[i] = version.stem
+			}
+			prompt := &survey.Confirm{
+				Message: fmt.Sprintf("Remove %s?", color.CyanString(strings.Join(stems, ", "))),
+			}
+			if err := survey.AskOne(prompt, &removeVersions); err != nil || !removeVersions {
+				color.HiBlack("No versions removed.")
+				removeVersions = false
+			}
+		}
+		if removeVersions || dryRunFlag {
+			for _, version := range unused {
+				if removeVersions {
+					pkgConf, err := pkgparse.ParseInstalledPkgConfig(cfg.PkgRepos, version.pkg, version.stem)
+					if ui.CodeOf(err) == ui.CodeRecipeNotFound {
+						pkgConf, err = &pkgparse.PkgConfig{Title: version.pkg}, nil
+					}
+					if err != nil {
+						return err
+					}
+					// removing through remove runs the version's pre-remove hooks
+					err = remove.RemovePkgVer(version.stem, version.using, version.pkg, pkgConf, func(format string, a ...any) {
+						fmt.Printf(format+"\n", a...)
+					})
+					if err != nil {
+						return fmt.Errorf("unable to remove %s: %v", version.stem, err)
+					}
+				}
+				report.Reclaimed += version.size
+				report.Versions = append(report.Versions, version.stem)
+				fmt.Printf("%s %s %s\n", verb, color.CyanString(version.stem), color.HiBlackString("(%s)", utils.FormatBytes(version.size)))
+			}
+		}
+
+		tmpEntries, err := os.ReadDir(utils.WebmanTmpDir)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		for _, entry := range tmpEntries {
+			fi, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			// newer files may belong to an install or refresh that's still running
+			if time.Since(fi.ModTime()) < utils.StaleTmpAge {
+				continue
+			}
+			tmpPath := filepath.Join(utils.WebmanTmpDir, entry.Name())
+			size, err := utils.DirSize(tmpPath)
+			if err != nil {
+				return err
+			}
+			if !dryRunFlag {
+				if err := os.RemoveAll(tmpPath); err != nil {
+					return fmt.Errorf("unable to remove temporary file %q: %v", tmpPath, err)
+				}
+			}
+			report.Reclaimed += size
+			report.TmpFiles = append(report.TmpFiles, entry.Name())
+			fmt.Printf("%s temporary file %s %s\n", verb, color.YellowString(entry.Name()), color.HiBlackString("(%s)", utils.FormatBytes(size)))
+		}
+
+		dangling, err := link.DanglingLinks(utils.WebmanBinDir)
+		if err != nil {
+			return err
+		}
+		for _, linkPath := range dangling {
+			if !dryRunFlag {
+				if err := os.Remove(linkPath); err != nil {
+					return fmt.Errorf("unable to remove dangling link %q: %v", linkPath, err)
+				}
+			}
+			report.Links = append(report.Links, filepath.Base(linkPath))
+			fmt.Printf("%s dangling link %s\n", verb, color.YellowString(filepath.Base(linkPath)))
+		}
+
+		if dryRunFlag {
+			color.Green("Would reclaim %s", utils.FormatBytes(report.Reclaimed))
+		} else {
+			color.Green("Reclaimed %s", utils.FormatBytes(report.Reclaimed))
+		}
+		if ui.IsJSONOutput() {
+			report.DryRun = dryRunFlag
+			return ui.PrintJSON(report)
+		}
+		return nil
+	},
+}
+
+// unusedVersion is an installed package version that gc can remove
+type unusedVersion struct {
+	pkg   string
+	stem  string
+	using *string
+	size  int64
+}
+
+// containsStem determines whether a version stem is in a list of stems
+func containsStem(stems []string, stem string) bool {
+	for _, s := range stems {
+		if s == stem {
+			return true
+		}
+	}
+	return false
+}
+
+// gcReport is the machine-readable result of a gc run
+type gcReport struct {
+	DryRun    bool     `json:"dry_run"`
+	Versions  []string `json:"versions"`
+	TmpFiles  []string `json:"tmp_files"`
+	Links     []string `json:"links"`
+	Reclaimed int64    `json:"reclaimed_bytes"`
+}
+
+func init() {
+	GcCmd.Flags().IntVarP(&keepFlag, "keep", "k", 0, "number of recent unused versions to keep for each package")
+	GcCmd.Flags().BoolVarP(&yesFlag, "yes", "y", false, "do not ask for confirmation")
+	GcCmd.Flags().BoolVarP(&dryRunFlag, "dry-run", "n", false, "report what would be removed without removing anything")
+}
@@ -0,0 +1,66 @@
+package gc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/candrewlee14/webman/pkgparse"
+	"github.com/candrewlee14/webman/utils"
+
+	"github.com/matryer/is"
+)
+
+func TestGc(t *testing.T) {
+	assert := is.New(t)
+
+	utils.Init(t.TempDir())
+	for _, ver := range []string{"1.9.0", "1.10.0", "1.11.0", "1.12.0"} {
+		stemPath := filepath.Join(utils.WebmanPkgDir, "go", utils.CreateStem("go", ver))
+		assert.NoErr(os.MkdirAll(stemPath, os.ModePerm))                                         // Should create version dir
+		assert.NoErr(os.WriteFile(filepath.Join(stemPath, "go"), make([]byte, 10), os.ModePerm)) // Should create version file
+	}
+	assert.NoErr(pkgparse.WriteUsing("go", utils.CreateStem("go", "1.10.0"))) // Should use an older version
+	for _, stem := range []string{"rg-13.0.0", "rg-14.0.0", "jq-1.6", "jq-1.7"} {
+		pkg, _ := utils.ParseStem(stem)
+		assert.NoErr(os.MkdirAll(filepath.Join(utils.WebmanPkgDir, pkg, stem), os.ModePerm)) // Should create version dir
+	}
+	assert.NoErr(pkgparse.WriteUsing("jq", "jq-1.5")) // Should use a version that isn't installed
+	stale := filepath.Join(utils.WebmanTmpDir, "go.tar.gz")
+	fresh := filepath.Join(utils.WebmanTmpDir, "rg.tar.gz")
+	assert.NoErr(os.WriteFile(stale, make([]byte, 5), os.ModePerm)) // Should create stale file
+	assert.NoErr(os.WriteFile(fresh, make([]byte, 5), os.ModePerm)) // Should create fresh file
+	old := time.Now().Add(-2 * utils.StaleTmpAge)
+	assert.NoErr(os.Chtimes(stale, old, old)) // Should age stale file
+
+	keepFlag, dryRunFlag = 1, true
+	defer func() { keepFlag, dryRunFlag, yesFlag = 0, false, false }()
+	assert.NoErr(GcCmd.RunE(GcCmd, nil)) // Should do a dry run
+	stems, err := utils.InstalledPkgVerStems("go")
+	assert.NoErr(err)           // Should list versions
+	assert.Equal(len(stems), 4) // Dry run should not remove versions
+	_, err = os.Stat(stale)
+	assert.NoErr(err) // Dry run should not remove temporary files
+
+	dryRunFlag = false
+	assert.True(GcCmd.RunE(GcCmd, nil) != nil) // Should not remove versions without confirmation
+	stems, err = utils.InstalledPkgVerStems("go")
+	assert.NoErr(err)           // Should list versions
+	assert.Equal(len(stems), 4) // Unconfirmed gc should not remove versions
+
+	yesFlag = true
+	assert.NoErr(GcCmd.RunE(GcCmd, nil)) // Should collect garbage
+	stems, err = utils.InstalledPkgVerStems("go")
+	assert.NoErr(err)                                       // Should list versions
+	assert.Equal(stems, []string{"go-1.10.0", "go-1.12.0"}) // Should keep the version in use and the newest unused one
+	_, err = os.Stat(stale)
+	assert.True(os.IsNotExist(err)) // Stale temporary file should be removed
+	_, err = os.Stat(fresh)
+	assert.NoErr(err) // Fresh temporary file should be kept
+	for _, pkg := range []string{"rg", "jq"} {
+		stems, err = utils.InstalledPkgVerStems(pkg)
+		assert.NoErr(err)           // Should list versions
+		assert.Equal(len(stems), 2) // Package without an installed version in use should be left alone
+	}
+}
@@ -5,20 +5,17 @@ import (
 	"fmt"
 
 	"github.com/candrewlee14/webman/cmd/add"
+	"github.com/candrewlee14/webman/cmd/complete"
 	"github.com/candrewlee14/webman/config"
-	"github.com/candrewlee14/webman/multiline"
 	"github.com/candrewlee14/webman/pkgparse"
-	"github.com/candrewlee14/webman/utils"
+	"github.com/candrewlee14/webman/ui"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
-var (
-	doRefresh bool
-	allFlag   bool
-)
+var allFlag bool
 
 var AddCmd = &cobra.Command{
 	Use:   "add [group]",
@@ -27,6 +24,7 @@ var AddCmd = &cobra.Command{
 
 The "group add" subcommand installs a group of packages.
 `,
+	ValidArgsFunction: complete.Single(complete.Groups),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 1 {
 			cmd.Help()
@@ -36,23 +34,8 @@ The "group add" subcommand installs a group of packages.
 		if err != nil {
 			return err
 		}
-		if utils.RecipeDirFlag == "" {
-			// only refresh if not using local
-			for _, pkgRepo := range cfg.PkgRepos {
-				shouldRefresh, err := pkgRepo.ShouldRefreshRecipes(cfg.RefreshInterval)
-				if err != nil {
-					return err
-				}
-				if shouldRefresh || doRefresh {
-					color.HiBlue("Refreshing package recipes for %q...", pkgRepo.Name)
-					if err = pkgRepo.RefreshRecipes(); err != nil {
-						fmt.Println(err)
-					} else {
-						color.HiBlue("%s%sRefreshed package recipes!",
-							multiline.MoveUp, multiline.ClearLine)
-					}
-				}
-			}
+		if err := cfg.RefreshDueRepos(); err != nil {
+			return err
 		}
 		group := args[0]
 		return InstallGroup(cfg, group)
@@ -60,50 +43,78 @@ The "group add" subcommand installs a group of packages.
 }
 
 func InstallGroup(cfg *config.Config, group string) error {
-	groupConf, repoPath, err := pkgparse.ParseGroupConfigLocal(cfg.PkgRepos, group)
+	groupPkgs, err := pkgparse.ParseGroupPackages(cfg.PkgRepos, group)
 	if err != nil {
 		return err
 	}
 
 	var pkgsToInstall []string
 	if allFlag {
-		pkgsToInstall = groupConf.Packages
+		for _, pkg := range groupPkgs {
+			if !pkg.Optional {
+				pkgsToInstall = append(pkgsToInstall, pkg.Arg())
+			}
+		}
 	} else {
-		pkgInfos, err := pkgparse.ParseGroupPackages(repoPath, groupConf.Packages)
+		if !ui.AreInteractivePromptsEnabled() {
+			return ui.Errorf(ui.CodePromptUnavailable, "cannot prompt for packages to install; pass --all to select every package in group %s", group)
+		}
+		recipeIndex, err := pkgparse.LoadIndex(cfg.PkgRepos)
 		if err != nil {
 			return err
 		}
-		infoLines := make([]string, len(pkgInfos))
-		for i, pkgInfo := range pkgInfos {
-			infoLines[i] = color.CyanString(pkgInfo.Title) + color.HiBlackString(" - ") + pkgInfo.Tagline
+		infoLines := make([]string, len(groupPkgs))
+		var defaults []int
+		for i, pkg := range groupPkgs {
+			pkgInfo := recipeIndex.FindPkg(pkg.Name)
+			if pkgInfo == nil {
+				return fmt.Errorf("no package recipe exists for %s", pkg.Name)
+			}
+			infoLines[i] = GroupPackageLine(pkg, pkgInfo)
+			if pkg.Default {
+				defaults = append(defaults, i)
+			}
 		}
 		prompt := &survey.MultiSelect{
 			Message:  "Select packages from group " + color.YellowString(group) + " to install:",
 			Options:  infoLines,
+			Default:  defaults,
 			PageSize: 10,
 		}
 		var indices []int
 		survey.AskOne(prompt, &indices)
 		for _, val := range indices {
-			pkgsToInstall = append(pkgsToInstall, groupConf.Packages[val])
+			pkgsToInstall = append(pkgsToInstall, groupPkgs[val].Arg())
 		}
 	}
 	if len(pkgsToInstall) == 0 {
 		color.HiBlack("No packages selected for installation.")
 	} else {
-		pkgs := add.InstallAllPkgs(cfg.PkgRepos, pkgsToInstall, false, true)
-		for _, pkg := range pkgs {
-			fmt.Print(pkg.PkgConf.InstallNotes())
+		pkgs, errs := add.InstallAllPkgs(cfg.PkgRepos, pkgsToInstall, false, true)
+		if err := add.PrintResults(pkgs, errs); err != nil {
+			return err
 		}
-		if len(pkgs) != len(pkgsToInstall) {
-			return errors.New("Not all packages installed successfully")
+		if len(errs) != 0 {
+			return ui.Errorf(ui.CodeInstallFailed, "Not all packages installed successfully")
 		}
 		color.Green("All %d selected packages from group %s are installed", len(pkgsToInstall), color.YellowString(group))
 	}
 	return nil
 }
 
+// GroupPackageLine describes a group package for prompts, with its version constraint if any
+func GroupPackageLine(pkg pkgparse.GroupPackage, pkgInfo *pkgparse.PkgIndexEntry) string {
+	line := color.CyanString(pkgInfo.Title)
+	if pkg.Version != "" {
+		line += "@" + color.MagentaString(pkg.Version)
+	}
+	line += color.HiBlackString(" - ") + pkgInfo.Tagline
+	if pkg.Optional {
+		line += color.HiBlackString(" (optional)")
+	}
+	return line
+}
+
 func init() {
-	AddCmd.Flags().BoolVar(&doRefresh, "refresh", false, "force refresh of package recipes")
-	AddCmd.Flags().BoolVarP(&allFlag, "all", "a", false, "add latest versions of all packages in group")
+	AddCmd.Flags().BoolVarP(&allFlag, "all", "a", false, "add latest versions of all packages in group, except optional ones")
 }
@@ -0,0 +1,110 @@
+package create
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/candrewlee14/webman/config"
+	"github.com/candrewlee14/webman/pkgparse"
+	"github.com/candrewlee14/webman/ui"
+	"github.com/candrewlee14/webman/utils"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	titleFlag       string
+	taglineFlag     string
+	descriptionFlag string
+	includeFlag     []string
+	forceFlag       bool
+)
+
+var CreateCmd = &cobra.Command{
+	Use:   "create [group] [pkg]...",
+	Short: "create a local group of packages",
+	Long: `
+The "group create" subcommand creates a personal group of packages in ~/.webman/groups.
+Local groups can be searched, added and upgraded like groups from package repositories,
+and take precedence over repository groups with the same name.`,
+	Example: `webman group create toolbox rg jq go@1.22.x
+webman group create backend --include toolbox k9s --tagline "backend tools"`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return cmd.Help()
+		}
+		group := args[0]
+		if err := pkgparse.ValidateGroupName(group); err != nil {
+			return ui.Errorf(ui.CodeInvalidArgs, "%v", err)
+		}
+		if len(args) == 1 && len(includeFlag) == 0 {
+			return ui.Errorf(ui.CodeInvalidArgs, "a group needs at least one package or included group")
+		}
+		if !forceFlag {
+			if _, err := os.Stat(pkgparse.LocalGroupPath(group)); err == nil {
+				return ui.Errorf(ui.CodeInvalidArgs, "local group %s already exists; pass --force to replace it or use \"webman group edit\"", group)
+			} else if !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+		}
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		groupConf := &pkgparse.PkgGroupConfig{
+			Title:       titleFlag,
+			Tagline:     taglineFlag,
+			Description: descriptionFlag,
+			Groups:      includeFlag,
+		}
+		for _, arg := range args[1:] {
+			repo, pkg, ver, err := utils.ParsePkgVer(arg)
+			if err != nil {
+				return ui.Errorf(ui.CodeInvalidArgs, "%v", err)
+			}
+			pkgRepos, err := config.SelectRepos(cfg.PkgRepos, repo)
+			if err != nil {
+				return ui.Errorf(ui.CodeInvalidArgs, "%v", err)
+			}
+			found, err := pkgparse.FindPkgRepos(pkgRepos, pkg)
+			if err != nil {
+				return err
+			}
+			if len(found) == 0 {
+				return ui.Errorf(ui.CodeRecipeNotFound, "no package recipe exists for %s", pkg)
+			}
+			groupConf.Packages = append(groupConf.Packages, pkgparse.GroupPackage{Name: pkg, Repo: repo, Version: ver})
+		}
+		for _, included := range includeFlag {
+			if included == group {
+				return ui.Errorf(ui.CodeInvalidArgs, "group %s cannot include itself", group)
+			}
+			if _, _, err := pkgparse.FindGroupPath(cfg.PkgRepos, included); err != nil {
+				return ui.Errorf(ui.CodeInvalidArgs, "%v", err)
+			}
+		}
+		if err := pkgparse.WriteLocalGroup(group, groupConf); err != nil {
+			return err
+		}
+		if ui.IsJSONOutput() {
+			return ui.PrintJSON(struct {
+				Group string `json:"group"`
+				Path  string `json:"path"`
+			}{group, pkgparse.LocalGroupPath(group)})
+		}
+		color.Green("Created local group %s with %d package(s)", color.YellowString(group), len(groupConf.Packages))
+		fmt.Printf("Install it with %s\n", color.CyanString("webman group add "+group))
+		return nil
+	},
+}
+
+func init() {
+	CreateCmd.Flags().StringVar(&titleFlag, "title", "", "group title")
+	CreateCmd.Flags().StringVar(&taglineFlag, "tagline", "", "group tagline")
+	CreateCmd.Flags().StringVar(&descriptionFlag, "description", "", "group description")
+	CreateCmd.Flags().StringSliceVar(&includeFlag, "include", nil, "other groups to include")
+	CreateCmd.Flags().BoolVarP(&forceFlag, "force", "f", false, "replace an existing local group")
+}
@@ -0,0 +1,114 @@
+package edit
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/candrewlee14/webman/cmd/complete"
+	"github.com/candrewlee14/webman/config"
+	"github.com/candrewlee14/webman/pkgparse"
+	"github.com/candrewlee14/webman/ui"
+	"github.com/candrewlee14/webman/utils"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var EditCmd = &cobra.Command{
+	Use:   "edit [group]",
+	Short: "edit a local group of packages",
+	Long: `
+The "group edit" subcommand opens a local group in $VISUAL or $EDITOR.
+Editing a group from a package repository first copies it to ~/.webman/groups,
+where the local copy takes precedence over the repository group.`,
+	Example:           `webman group edit toolbox`,
+	ValidArgsFunction: complete.Single(complete.Groups),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return cmd.Help()
+		}
+		group := args[0]
+		if err := pkgparse.ValidateGroupName(group); err != nil {
+			return ui.Errorf(ui.CodeInvalidArgs, "%v", err)
+		}
+		if !ui.AreInteractivePromptsEnabled() {
+			return ui.Errorf(ui.CodePromptUnavailable, "cannot open an editor without an interactive terminal")
+		}
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		localPath := pkgparse.LocalGroupPath(group)
+		original, err := os.ReadFile(localPath)
+		if errors.Is(err, fs.ErrNotExist) {
+			groupPath, repo, findErr := pkgparse.FindGroupPath(cfg.PkgRepos, group)
+			if findErr != nil {
+				return ui.Errorf(ui.CodeRecipeNotFound, "no group %s exists; create it with \"webman group create\"", group)
+			}
+			if original, err = os.ReadFile(groupPath); err != nil {
+				return err
+			}
+			fmt.Printf("Copying group %s from %s to your local groups\n", color.YellowString(group), color.CyanString(repo))
+			if err := os.MkdirAll(utils.WebmanGroupDir, os.ModePerm); err != nil {
+				return err
+			}
+			if err := os.WriteFile(localPath, original, 0o644); err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+
+		for {
+			if err := runEditor(localPath); err != nil {
+				return err
+			}
+			data, err := os.ReadFile(localPath)
+			if err != nil {
+				return err
+			}
+			lintErr := pkgparse.LintGroupData(group, data)
+			if lintErr == nil {
+				color.Green("Saved local group %s", color.YellowString(group))
+				return nil
+			}
+			color.Red("Invalid group: %v", lintErr)
+			again := true
+			if err := survey.AskOne(&survey.Confirm{Message: "Edit again?", Default: true}, &again); err != nil || !again {
+				if err := os.WriteFile(localPath, original, 0o644); err != nil {
+					return err
+				}
+				return fmt.Errorf("discarded invalid changes to group %s", group)
+			}
+		}
+	},
+}
+
+// runEditor opens a file in the user's editor, waiting for it to close
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if utils.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+	// editors are often configured with arguments, like "code --wait"
+	fields := strings.Fields(editor)
+	editorCmd := exec.Command(fields[0], append(fields[1:], path)...)
+	editorCmd.Stdin = os.Stdin
+	editorCmd.Stdout = os.Stdout
+	editorCmd.Stderr = os.Stderr
+	if err := editorCmd.Run(); err != nil {
+		return fmt.Errorf("editor %q failed: %v", editor, err)
+	}
+	return nil
+}
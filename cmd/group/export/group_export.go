@@ -0,0 +1,64 @@
+package export
+
+import (
+	"os"
+
+	"github.com/candrewlee14/webman/cmd/complete"
+	"github.com/candrewlee14/webman/config"
+	"github.com/candrewlee14/webman/pkgparse"
+	"github.com/candrewlee14/webman/ui"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var fileFlag string
+
+var ExportCmd = &cobra.Command{
+	Use:   "export [group]",
+	Short: "export a group file",
+	Long: `
+The "group export" subcommand prints the file of a local or repository group,
+so it can be shared or added to a package repository.`,
+	Example: `webman group export toolbox
+webman group export toolbox --file toolbox.webman-group.yml`,
+	ValidArgsFunction: complete.Single(complete.Groups),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return cmd.Help()
+		}
+		group := args[0]
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		groupPath, repo, err := pkgparse.FindGroupPath(cfg.PkgRepos, group)
+		if err != nil {
+			return ui.Errorf(ui.CodeRecipeNotFound, "%v", err)
+		}
+		data, err := os.ReadFile(groupPath)
+		if err != nil {
+			return err
+		}
+		if fileFlag != "" {
+			if err := os.WriteFile(fileFlag, data, 0o644); err != nil {
+				return err
+			}
+			color.Green("Exported %s group %s to %s", repo, color.YellowString(group), fileFlag)
+			return nil
+		}
+		if ui.IsJSONOutput() {
+			return ui.PrintJSON(struct {
+				Group string `json:"group"`
+				Repo  string `json:"repo"`
+				Data  string `json:"data"`
+			}{group, repo, string(data)})
+		}
+		_, err = os.Stdout.Write(data)
+		return err
+	},
+}
+
+func init() {
+	ExportCmd.Flags().StringVarP(&fileFlag, "file", "f", "", "write the group to a file instead of printing it")
+}
@@ -2,8 +2,12 @@ package group
 
 import (
 	groupadd "github.com/candrewlee14/webman/cmd/group/add"
+	groupcreate "github.com/candrewlee14/webman/cmd/group/create"
+	groupedit "github.com/candrewlee14/webman/cmd/group/edit"
+	groupexport "github.com/candrewlee14/webman/cmd/group/export"
 	groupremove "github.com/candrewlee14/webman/cmd/group/remove"
 	groupsearch "github.com/candrewlee14/webman/cmd/group/search"
+	groupstatus "github.com/candrewlee14/webman/cmd/group/status"
 	groupupgrade "github.com/candrewlee14/webman/cmd/group/upgrade"
 
 	"github.com/spf13/cobra"
@@ -20,12 +24,17 @@ The "group" subcommand manages a group of packages.
 	Example: `
 webman group add
 webman group remove
+webman group create toolbox rg jq
 `,
 }
 
 func init() {
 	GroupCmd.AddCommand(groupadd.AddCmd)
+	GroupCmd.AddCommand(groupcreate.CreateCmd)
+	GroupCmd.AddCommand(groupedit.EditCmd)
+	GroupCmd.AddCommand(groupexport.ExportCmd)
 	GroupCmd.AddCommand(groupremove.RemoveCmd)
 	GroupCmd.AddCommand(groupsearch.SearchCmd)
+	GroupCmd.AddCommand(groupstatus.StatusCmd)
 	GroupCmd.AddCommand(groupupgrade.UpgradeCmd)
 }
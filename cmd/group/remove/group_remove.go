@@ -2,11 +2,12 @@ package remove
 
 import (
 	"fmt"
-	"os"
 
+	"github.com/candrewlee14/webman/cmd/complete"
 	"github.com/candrewlee14/webman/cmd/remove"
 	"github.com/candrewlee14/webman/config"
 	"github.com/candrewlee14/webman/pkgparse"
+	"github.com/candrewlee14/webman/ui"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/fatih/color"
@@ -22,6 +23,7 @@ var RemoveCmd = &cobra.Command{
 
 The "group remove" subcommand removes a group of packages.
 `,
+	ValidArgsFunction: complete.Single(complete.Groups),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 1 {
 			return cmd.Help()
@@ -32,18 +34,25 @@ The "group remove" subcommand removes a group of packages.
 		}
 
 		group := args[0]
-		groupConf, _, err := pkgparse.ParseGroupConfigLocal(cfg.PkgRepos, group)
+		groupPkgs, err := pkgparse.ParseGroupPackages(cfg.PkgRepos, group)
 		if err != nil {
 			return err
 		}
+		pkgNames := make([]string, 0, len(groupPkgs))
+		for _, pkg := range groupPkgs {
+			pkgNames = append(pkgNames, pkg.Name)
+		}
 
 		var pkgsToRemove []string
 		if allFlag {
-			pkgsToRemove = groupConf.Packages
+			pkgsToRemove = pkgNames
 		} else {
+			if !ui.AreInteractivePromptsEnabled() {
+				return ui.Errorf(ui.CodePromptUnavailable, "cannot prompt for packages to remove; pass --all to select every package in group %s", group)
+			}
 			surveyPrompt := &survey.MultiSelect{
 				Message:  "Select packages from group " + color.YellowString(group) + " to " + color.RedString("remove") + ":",
-				Options:  groupConf.Packages,
+				Options:  pkgNames,
 				PageSize: 10,
 			}
 			err := survey.AskOne(surveyPrompt, &pkgsToRemove)
@@ -53,10 +62,15 @@ The "group remove" subcommand removes a group of packages.
 		}
 		if len(pkgsToRemove) == 0 {
 			color.HiBlack("No packages selected for removal.")
-			os.Exit(0)
+			return printRemoved(group, nil)
 		}
+		var removedPkgs []string
 		for _, pkg := range pkgsToRemove {
-			pkgConf, err := pkgparse.ParsePkgConfigLocal(cfg.PkgRepos, pkg)
+			pkgConf, err := remove.InstalledPkgConf(cfg.PkgRepos, pkg)
+			if ui.CodeOf(err) == ui.CodeNotInstalled {
+				color.HiBlack("%s was not previously installed", pkg)
+				continue
+			}
 			if err != nil {
 				return err
 			}
@@ -65,6 +79,7 @@ The "group remove" subcommand removes a group of packages.
 				return err
 			}
 			if removed {
+				removedPkgs = append(removedPkgs, pkg)
 				fmt.Print(pkgConf.RemoveNotes())
 				fmt.Println("Removed", color.CyanString(pkg))
 			} else {
@@ -72,10 +87,24 @@ The "group remove" subcommand removes a group of packages.
 			}
 		}
 		fmt.Printf("All %d selected packages in group %s are uninstalled.\n", len(pkgsToRemove), color.YellowString(group))
-		return nil
+		return printRemoved(group, removedPkgs)
 	},
 }
 
+// printRemoved prints the removed packages of a group when using JSON output
+func printRemoved(group string, pkgs []string) error {
+	if !ui.IsJSONOutput() {
+		return nil
+	}
+	if pkgs == nil {
+		pkgs = []string{}
+	}
+	return ui.PrintJSON(struct {
+		Group   string   `json:"group"`
+		Removed []string `json:"removed"`
+	}{group, pkgs})
+}
+
 func init() {
 	RemoveCmd.Flags().BoolVarP(&allFlag, "all", "a", false, "remove all versions of the packages in group")
 }
@@ -3,14 +3,13 @@ package search
 import (
 	"fmt"
 	"io"
-	"os"
 	"sort"
 	"strings"
 
 	"github.com/candrewlee14/webman/cmd/group/add"
 	"github.com/candrewlee14/webman/config"
 	"github.com/candrewlee14/webman/pkgparse"
-	"github.com/candrewlee14/webman/utils"
+	"github.com/candrewlee14/webman/ui"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/fatih/color"
@@ -18,14 +17,14 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var doRefresh bool
-
 var SearchCmd = &cobra.Command{
 	Use:   "search",
 	Short: "search for a group",
 	Long: `
-The "search" subcommand starts an interactive window to find and display info about a group`,
-	Example: `webman group search`,
+The "search" subcommand starts an interactive window to find and display info about a group.
+When not running interactively, it prints every group from all repositories instead.`,
+	Example: `webman group search
+webman group search --output json`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 0 {
 			return cmd.Help()
@@ -34,46 +33,29 @@ The "search" subcommand starts an interactive window to find and display info ab
 		if err != nil {
 			return err
 		}
-		// if local recipe flag is not set
-		if utils.RecipeDirFlag == "" {
-			// only refresh if not using local
-			for _, pkgRepo := range cfg.PkgRepos {
-				shouldRefresh, err := pkgRepo.ShouldRefreshRecipes(cfg.RefreshInterval)
-				if err != nil {
-					return err
-				}
-				if shouldRefresh || doRefresh {
-					color.HiBlue("Refreshing package recipes for %q...", pkgRepo.Name)
-					if err = pkgRepo.RefreshRecipes(); err != nil {
-						color.Red("%v", err)
-					}
-				}
-			}
+		if err := cfg.RefreshDueRepos(); err != nil {
+			return err
 		}
 
-		groupInfos := make([]*pkgparse.PkgGroupConfig, 0)
-		for _, pkgRepo := range cfg.PkgRepos {
-			files, err := os.ReadDir(pkgRepo.GroupPath())
-			if err != nil {
-				return err
-			}
-			for _, file := range files {
-				group := strings.Split(file.Name(), utils.GroupRecipeExt)[0]
-				groupInfo, err := pkgparse.ParseGroupConfigInRepo(pkgRepo, group)
-				if err != nil {
-					return err
-				}
-				groupInfos = append(groupInfos, groupInfo)
-			}
+		recipeIndex, err := pkgparse.LoadIndex(cfg.PkgRepos)
+		if err != nil {
+			return err
 		}
-		sort.Slice(groupInfos, func(i, j int) bool {
-			return groupInfos[i].Title < groupInfos[j].Title
+		groupInfos := recipeIndex.Groups()
+		sort.SliceStable(groupInfos, func(i, j int) bool {
+			return groupInfos[i].Name < groupInfos[j].Name
 		})
+		if !ui.AreInteractivePromptsEnabled() {
+			return printGroups(groupInfos, len(cfg.PkgRepos) > 1)
+		}
 
 		idx, err := fuzzyfinder.Find(
 			groupInfos,
 			func(i int) string {
-				return groupInfos[i].Title + " - " + groupInfos[i].Tagline
+				if groupInfos[i].Repo == pkgparse.LocalGroupRepo {
+					return groupInfos[i].Name + " (local) - " + groupInfos[i].Tagline
+				}
+				return groupInfos[i].Name + " - " + groupInfos[i].Tagline
 			},
 			fuzzyfinder.WithPreviewWindow(func(i, w, h int) string {
 				if i == -1 {
@@ -89,13 +71,16 @@ The "search" subcommand starts an interactive window to find and display info ab
 					"🗐 Package List",
 					strings.Join(groupInfos[i].Packages, ", "),
 				)
+				if len(groupInfos[i].Groups) != 0 {
+					preview += fmt.Sprintf("\n%s:\n%s\n", "🗀 Includes Groups", strings.Join(groupInfos[i].Groups, ", "))
+				}
 				return wrapText(preview, w)
 			}))
 		if err != nil {
 			color.HiBlack("No group selected.")
 			return nil
 		}
-		groupName := groupInfos[idx].Title
+		groupName := groupInfos[idx].Name
 		prompt := &survey.Confirm{
 			Message: "Would you like to install the latest version of " + color.CyanString(groupName) + "?",
 		}
@@ -108,8 +93,51 @@ The "search" subcommand starts an interactive window to find and display info ab
 	},
 }
 
-func init() {
-	SearchCmd.Flags().BoolVar(&doRefresh, "refresh", false, "force refresh of package recipes")
+// GroupJSONMatch is the machine-readable form of a group listed by search
+type GroupJSONMatch struct {
+	Name        string   `json:"name"`
+	Title       string   `json:"title"`
+	Tagline     string   `json:"tagline"`
+	Description string   `json:"description"`
+	Packages    []string `json:"packages"`
+	Groups      []string `json:"groups"`
+	Repo        string   `json:"repo"`
+}
+
+func printGroups(groupInfos []pkgparse.GroupIndexEntry, showRepo bool) error {
+	if ui.IsJSONOutput() {
+		jsonMatches := make([]GroupJSONMatch, 0, len(groupInfos))
+		for _, groupInfo := range groupInfos {
+			// scripts get empty lists rather than null
+			pkgs, groups := []string{}, []string{}
+			pkgs = append(pkgs, groupInfo.Packages...)
+			groups = append(groups, groupInfo.Groups...)
+			jsonMatches = append(jsonMatches, GroupJSONMatch{
+				Name:        groupInfo.Name,
+				Title:       groupInfo.Title,
+				Tagline:     groupInfo.Tagline,
+				Description: groupInfo.Description,
+				Packages:    pkgs,
+				Groups:      groups,
+				Repo:        groupInfo.Repo,
+			})
+		}
+		return ui.PrintJSON(struct {
+			Groups []GroupJSONMatch `json:"groups"`
+		}{jsonMatches})
+	}
+	if len(groupInfos) == 0 {
+		color.HiBlack("No groups found.")
+		return nil
+	}
+	for _, groupInfo := range groupInfos {
+		name := color.CyanString(groupInfo.Name)
+		if showRepo || groupInfo.Repo == pkgparse.LocalGroupRepo {
+			name = color.HiBlackString(groupInfo.Repo+"/") + name
+		}
+		fmt.Println(name + color.HiBlackString(" - ") + groupInfo.Tagline)
+	}
+	return nil
 }
 
 func wrapText(text string, width int) string {
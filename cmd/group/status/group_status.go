@@ -0,0 +1,256 @@
+package status
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/candrewlee14/webman/cmd/complete"
+	"github.com/candrewlee14/webman/config"
+	"github.com/candrewlee14/webman/pkgparse"
+	"github.com/candrewlee14/webman/ui"
+	"github.com/candrewlee14/webman/utils"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var StatusCmd = &cobra.Command{
+	Use:   "status [group]",
+	Short: "show which packages of a group are installed and up to date",
+	Long: `
+The "group status" subcommand lists each package of a group with its installed versions,
+the version in use, the latest version allowed by the group and whether this platform is supported.
+
+The group is satisfied when every required package that supports this platform
+has a version installed that matches the group. Otherwise the command exits with an error,
+so scripts can check whether a machine is ready.`,
+	Example: `webman group status modern-unix
+webman group status backend-dev --output json`,
+	ValidArgsFunction: complete.Single(complete.Groups),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return cmd.Help()
+		}
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		if err := cfg.RefreshDueRepos(); err != nil {
+			return err
+		}
+		group := args[0]
+		groupPkgs, err := pkgparse.ParseGroupPackages(cfg.PkgRepos, group)
+		if err != nil {
+			return err
+		}
+		status := GroupStatus{Group: group, Members: GetMemberStatuses(cfg.PkgRepos, groupPkgs)}
+		status.Satisfied = true
+		for _, member := range status.Members {
+			if !member.Optional && member.Supported && !member.Satisfied {
+				status.Satisfied = false
+			}
+		}
+		if ui.IsJSONOutput() {
+			if err := ui.PrintJSON(status); err != nil {
+				return err
+			}
+		} else {
+			printStatus(status)
+		}
+		if !status.Satisfied {
+			return ui.Errorf(ui.CodeGroupUnsatisfied, "group %s is not satisfied", group)
+		}
+		return nil
+	},
+}
+
+// GroupStatus is the status of every package in a group
+type GroupStatus struct {
+	Group     string         `json:"group"`
+	Satisfied bool           `json:"satisfied"`
+	Members   []MemberStatus `json:"members"`
+}
+
+// Member states
+const (
+	StateMissing     = "missing"
+	StateOutdated    = "outdated"
+	StateUpToDate    = "up_to_date"
+	StateUnsupported = "unsupported"
+	StateUnknown     = "unknown"
+)
+
+// MemberStatus is the status of a package in a group
+type MemberStatus struct {
+	Name string `json:"name"`
+	// Version is the version or version constraint the group asks for, if any
+	Version  string `json:"version,omitempty"`
+	Optional bool   `json:"optional"`
+	// Installed are the installed versions, oldest first
+	Installed []string `json:"installed"`
+	Using     string   `json:"using,omitempty"`
+	// Current is the installed version that satisfies the group, preferring the version in use
+	Current string `json:"current,omitempty"`
+	// Latest is the newest version allowed by the group
+	Latest    string `json:"latest,omitempty"`
+	LatestErr string `json:"latest_error,omitempty"`
+	Supported bool   `json:"supported"`
+	Satisfied bool   `json:"satisfied"`
+	// Held packages are skipped by upgrades
+	Held  bool   `json:"held"`
+	State string `json:"state"`
+
+	Pkg     pkgparse.GroupPackage `json:"-"`
+	PkgConf *pkgparse.PkgConfig   `json:"-"`
+}
+
+// GetMemberStatuses looks up the status of group packages concurrently, returning them in the same order
+func GetMemberStatuses(pkgRepos []*config.PkgRepo, groupPkgs []pkgparse.GroupPackage) []MemberStatus {
+	statuses := make([]MemberStatus, len(groupPkgs))
+	var wg sync.WaitGroup
+	wg.Add(len(groupPkgs))
+	for i, pkg := range groupPkgs {
+		i := i
+		pkg := pkg
+		go func() {
+			defer wg.Done()
+			statuses[i] = getMemberStatus(pkgRepos, pkg)
+		}()
+	}
+	wg.Wait()
+	return statuses
+}
+
+func getMemberStatus(pkgRepos []*config.PkgRepo, pkg pkgparse.GroupPackage) MemberStatus {
+	status := MemberStatus{
+		Name:      pkg.Name,
+		Version:   pkg.Version,
+		Optional:  pkg.Optional,
+		Installed: []string{},
+		Pkg:       pkg,
+	}
+	stems, err := utils.InstalledPkgVerStems(pkg.Name)
+	if err != nil && !os.IsNotExist(err) {
+		status.LatestErr = err.Error()
+	}
+	for _, stem := range stems {
+		_, ver := utils.ParseStem(stem)
+		status.Installed = append(status.Installed, ver)
+	}
+	if using, err := pkgparse.CheckUsing(pkg.Name); err == nil && using != nil {
+		_, status.Using = utils.ParseStem(*using)
+	}
+	if status.Using != "" && utils.SatisfiesVersion(pkg.Version, status.Using) {
+		status.Current = status.Using
+	} else {
+		for _, ver := range status.Installed {
+			if utils.SatisfiesVersion(pkg.Version, ver) {
+				status.Current = ver
+			}
+		}
+	}
+	status.Satisfied = status.Current != ""
+	if held, err := pkgparse.IsHeld(pkg.Name); err == nil {
+		status.Held = held
+	}
+
+	selected, err := config.SelectRepos(pkgRepos, pkg.Repo)
+	if err == nil {
+		status.PkgConf, err = pkgparse.ParsePkgConfigLocal(selected, pkg.Name)
+	}
+	if err != nil {
+		status.LatestErr = err.Error()
+		status.State = StateUnknown
+		return status
+	}
+	status.Supported = status.PkgConf.SupportsPlatform(pkgparse.GOOStoPkgOs[utils.GOOS], utils.GOARCH)
+	switch {
+	case pkg.Version != "" && !utils.IsVersionConstraint(pkg.Version):
+		status.Latest = pkg.Version
+	case pkg.Version != "":
+		latest, err := status.PkgConf.GetMatchingVersion(pkg.Version)
+		if err != nil {
+			status.LatestErr = err.Error()
+		} else {
+			status.Latest = *latest
+		}
+	default:
+		latest, err := status.PkgConf.GetLatestVersion()
+		if err != nil {
+			status.LatestErr = err.Error()
+		} else {
+			status.Latest = *latest
+		}
+	}
+
+	switch {
+	case !status.Supported:
+		status.State = StateUnsupported
+	case !status.Satisfied:
+		status.State = StateMissing
+	case status.Latest == "":
+		status.State = StateUnknown
+	case utils.CompareVersions(status.Current, status.Latest) < 0:
+		status.State = StateOutdated
+	default:
+		status.State = StateUpToDate
+	}
+	return status
+}
+
+func printStatus(status GroupStatus) {
+	nameWidth := 0
+	for _, member := range status.Members {
+		if len(member.Name) > nameWidth {
+			nameWidth = len(member.Name)
+		}
+	}
+	for _, member := range status.Members {
+		var state string
+		switch member.State {
+		case StateUpToDate:
+			state = color.GreenString("✓ up to date")
+		case StateOutdated:
+			state = color.YellowString("↑ %s available", member.Latest)
+		case StateMissing:
+			state = color.RedString("✗ not installed")
+			if member.Version != "" {
+				state = color.RedString("✗ no %s installed", member.Version)
+			}
+		case StateUnsupported:
+			state = color.HiBlackString("- unsupported on %s/%s", pkgparse.GOOStoPkgOs[utils.GOOS], utils.GOARCH)
+		case StateUnknown:
+			if member.PkgConf == nil {
+				state = color.RedString("? %s", member.LatestErr)
+			} else {
+				state = color.HiBlackString("? latest version unknown")
+			}
+		}
+		installed := make([]string, len(member.Installed))
+		for i, ver := range member.Installed {
+			installed[i] = ver
+			if ver == member.Using {
+				installed[i] = color.GreenString("%s (using)", ver)
+			}
+		}
+		installedStr := strings.Join(installed, ", ")
+		if installedStr == "" {
+			installedStr = color.HiBlackString("none")
+		}
+		name := color.CyanString("%-*s", nameWidth, member.Name)
+		if member.Optional {
+			name += color.HiBlackString(" (optional)")
+		}
+		if member.Held {
+			name += color.HiBlackString(" (held)")
+		}
+		fmt.Printf("%s  %s  %s\n", name, state, color.HiBlackString("installed: ")+installedStr)
+	}
+	// an unsatisfied group is reported by the returned error
+	if status.Satisfied {
+		fmt.Println()
+		color.Green("Group %s is satisfied", status.Group)
+	}
+}
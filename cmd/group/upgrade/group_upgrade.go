@@ -5,10 +5,11 @@ import (
 	"fmt"
 
 	"github.com/candrewlee14/webman/cmd/add"
+	"github.com/candrewlee14/webman/cmd/complete"
+	groupstatus "github.com/candrewlee14/webman/cmd/group/status"
 	"github.com/candrewlee14/webman/config"
-	"github.com/candrewlee14/webman/multiline"
 	"github.com/candrewlee14/webman/pkgparse"
-	"github.com/candrewlee14/webman/utils"
+	"github.com/candrewlee14/webman/ui"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/fatih/color"
@@ -16,8 +17,8 @@ import (
 )
 
 var (
-	doRefresh bool
-	allFlag   bool
+	allFlag            bool
+	installMissingFlag bool
 )
 
 var UpgradeCmd = &cobra.Command{
@@ -25,8 +26,14 @@ var UpgradeCmd = &cobra.Command{
 	Short: "upgrade a group of packages",
 	Long: `
 
-The "group upgrade" subcommand upgrades a group of packages.
+The "group upgrade" subcommand upgrades the installed packages of a group to the latest versions the group allows.
+Packages that are already up to date or held with "webman hold" are skipped.
+With --install-missing, packages of the group that aren't installed yet are installed too.
 `,
+	Example: `webman group upgrade modern-unix
+webman group upgrade modern-unix --all
+webman group upgrade modern-unix --all --install-missing`,
+	ValidArgsFunction: complete.Single(complete.Groups),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 1 {
 			cmd.Help()
@@ -36,23 +43,8 @@ The "group upgrade" subcommand upgrades a group of packages.
 		if err != nil {
 			return err
 		}
-		if utils.RecipeDirFlag == "" {
-			// only refresh if not using local
-			for _, pkgRepo := range cfg.PkgRepos {
-				shouldRefresh, err := pkgRepo.ShouldRefreshRecipes(cfg.RefreshInterval)
-				if err != nil {
-					return err
-				}
-				if shouldRefresh || doRefresh {
-					color.HiBlue("Refreshing package recipes for %q...", pkgRepo.Name)
-					if err = pkgRepo.RefreshRecipes(); err != nil {
-						fmt.Println(err)
-					} else {
-						color.HiBlue("%s%sRefreshed package recipes!",
-							multiline.MoveUp, multiline.ClearLine)
-					}
-				}
-			}
+		if err := cfg.RefreshDueRepos(); err != nil {
+			return err
 		}
 		group := args[0]
 		return UpgradeGroup(cfg, group)
@@ -60,50 +52,117 @@ The "group upgrade" subcommand upgrades a group of packages.
 }
 
 func UpgradeGroup(cfg *config.Config, group string) error {
-	groupConf, repoPath, err := pkgparse.ParseGroupConfigLocal(cfg.PkgRepos, group)
+	groupPkgs, err := pkgparse.ParseGroupPackages(cfg.PkgRepos, group)
 	if err != nil {
 		return err
 	}
 
-	var pkgsToInstall []string
-	if allFlag {
-		pkgsToInstall = groupConf.Packages
-	} else {
-		pkgInfos, err := pkgparse.ParseGroupPackages(repoPath, groupConf.Packages)
-		if err != nil {
-			return err
+	candidates, skipped := upgradeCandidates(groupstatus.GetMemberStatuses(cfg.PkgRepos, groupPkgs), installMissingFlag)
+	for _, reason := range skipped {
+		color.HiBlack("%s", reason)
+	}
+	if len(candidates) == 0 {
+		color.HiBlack("No packages from group %s need upgrading.", group)
+		return nil
+	}
+
+	selected := candidates
+	if !allFlag {
+		if !ui.AreInteractivePromptsEnabled() {
+			return ui.Errorf(ui.CodePromptUnavailable, "cannot prompt for packages to upgrade; pass --all to upgrade every package in group %s", group)
 		}
-		infoLines := make([]string, len(pkgInfos))
-		for i, pkgInfo := range pkgInfos {
-			infoLines[i] = color.CyanString(pkgInfo.Title) + color.HiBlackString(" - ") + pkgInfo.Tagline
+		options := make([]string, len(candidates))
+		defaults := make([]int, len(candidates))
+		for i, member := range candidates {
+			options[i] = upgradeLine(member)
+			defaults[i] = i
 		}
 		prompt := &survey.MultiSelect{
-			Message:  "Select packages from group " + color.YellowString(group) + " to install:",
-			Options:  infoLines,
+			Message:  "Select packages from group " + color.YellowString(group) + " to upgrade:",
+			Options:  options,
+			Default:  defaults,
 			PageSize: 10,
 		}
 		var indices []int
-		survey.AskOne(prompt, &indices)
+		if err := survey.AskOne(prompt, &indices); err != nil {
+			return fmt.Errorf("Prompt failed %v\n", err)
+		}
+		selected = nil
 		for _, val := range indices {
-			pkgsToInstall = append(pkgsToInstall, groupConf.Packages[val])
+			selected = append(selected, candidates[val])
 		}
 	}
-	if len(pkgsToInstall) == 0 {
-		color.HiBlack("No packages selected for installation.")
-	} else {
-		pkgs := add.InstallAllPkgs(cfg.PkgRepos, pkgsToInstall, true, true)
-		for _, pkg := range pkgs {
-			fmt.Print(pkg.PkgConf.InstallNotes())
-		}
-		if len(pkgs) != len(pkgsToInstall) {
-			return errors.New("Not all packages installed successfully")
+	if len(selected) == 0 {
+		color.HiBlack("No packages selected for upgrade.")
+		return nil
+	}
+	pkgsToInstall := make([]string, 0, len(selected))
+	for _, member := range selected {
+		target := member.Pkg
+		if member.Latest != "" {
+			target.Version = member.Latest
 		}
-		color.Green("All %d selected packages from group %s are installed", len(pkgsToInstall), color.YellowString(group))
+		pkgsToInstall = append(pkgsToInstall, target.Arg())
+	}
+	pkgs, errs := add.InstallAllPkgs(cfg.PkgRepos, pkgsToInstall, true, true)
+	if err := add.PrintResults(pkgs, errs); err != nil {
+		return err
 	}
+	if len(errs) != 0 {
+		return ui.Errorf(ui.CodeInstallFailed, "Not all packages upgraded successfully")
+	}
+	color.Green("All %d selected packages from group %s are upgraded", len(selected), color.YellowString(group))
 	return nil
 }
 
+// upgradeCandidates selects the group members to upgrade: installed members that are outdated,
+// or that have no installed version the group allows, skipping held ones.
+// With installMissing, required members that aren't installed are selected too.
+// It also returns why each other installed member was skipped.
+func upgradeCandidates(members []groupstatus.MemberStatus, installMissing bool) ([]groupstatus.MemberStatus, []string) {
+	var candidates []groupstatus.MemberStatus
+	var skipped []string
+	for _, member := range members {
+		switch {
+		case len(member.Installed) == 0:
+			if installMissing && member.State == groupstatus.StateMissing && !member.Optional {
+				candidates = append(candidates, member)
+			}
+		case member.Held:
+			skipped = append(skipped, fmt.Sprintf("%s is held at %s", member.Name, member.Current))
+		case member.State == groupstatus.StateOutdated:
+			candidates = append(candidates, member)
+		case member.State == groupstatus.StateMissing:
+			// installed, but no installed version matches the group
+			candidates = append(candidates, member)
+		case member.State == groupstatus.StateUpToDate:
+			skipped = append(skipped, fmt.Sprintf("%s is up to date (%s)", member.Name, member.Current))
+		case member.State == groupstatus.StateUnsupported:
+			skipped = append(skipped, fmt.Sprintf("%s is not supported on this platform", member.Name))
+		default:
+			skipped = append(skipped, fmt.Sprintf("%s: unable to determine the latest version: %s", member.Name, member.LatestErr))
+		}
+	}
+	return candidates, skipped
+}
+
+// upgradeLine describes an upgrade for the selection prompt, like "rg 13.0.0 → 14.1.0"
+func upgradeLine(member groupstatus.MemberStatus) string {
+	current := member.Current
+	if current == "" && member.Using != "" {
+		current = member.Using
+	}
+	if current == "" {
+		current = "not installed"
+	}
+	target := member.Latest
+	if target == "" {
+		target = "latest"
+	}
+	return fmt.Sprintf("%s %s → %s", color.CyanString(member.Name), color.HiBlackString(current), color.MagentaString(target))
+}
+
 func init() {
-	UpgradeCmd.Flags().BoolVar(&doRefresh, "refresh", false, "force refresh of package recipes")
-	UpgradeCmd.Flags().BoolVarP(&allFlag, "all", "a", false, "add latest versions of all packages in group")
+	UpgradeCmd.Flags().BoolVarP(&allFlag, "all", "a", false, "upgrade all outdated packages in group without prompting")
+	UpgradeCmd.Flags().BoolVar(&installMissingFlag, "install-missing", false, "also install packages of the group that aren't installed")
 }
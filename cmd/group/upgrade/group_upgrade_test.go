@@ -0,0 +1,44 @@
+package add
+
+import (
+	"testing"
+
+	groupstatus "github.com/candrewlee14/webman/cmd/group/status"
+
+	"github.com/matryer/is"
+)
+
+func TestUpgradeCandidates(t *testing.T) {
+	assert := is.New(t)
+
+	members := []groupstatus.MemberStatus{
+		{Name: "outdated", Installed: []string{"1.0.0"}, Current: "1.0.0", Latest: "1.1.0", State: groupstatus.StateOutdated},
+		{Name: "held", Installed: []string{"1.0.0"}, Current: "1.0.0", Latest: "1.1.0", State: groupstatus.StateOutdated, Held: true},
+		{Name: "current", Installed: []string{"1.1.0"}, Current: "1.1.0", Latest: "1.1.0", State: groupstatus.StateUpToDate},
+		{Name: "mismatched", Installed: []string{"1.0.0"}, Version: "2.x", Latest: "2.1.0", State: groupstatus.StateMissing},
+		{Name: "missing", Latest: "1.0.0", State: groupstatus.StateMissing},
+		{Name: "optional", Latest: "1.0.0", State: groupstatus.StateMissing, Optional: true},
+		{Name: "unsupported", Installed: []string{"1.0.0"}, State: groupstatus.StateUnsupported},
+		{Name: "unknown", Installed: []string{"1.0.0"}, State: groupstatus.StateUnknown, LatestErr: "offline"},
+	}
+	names := func(members []groupstatus.MemberStatus) []string {
+		var names []string
+		for _, member := range members {
+			names = append(names, member.Name)
+		}
+		return names
+	}
+
+	candidates, skipped := upgradeCandidates(members, false)
+	assert.Equal(names(candidates), []string{"outdated", "mismatched"}) // Should only upgrade installed members that need it
+	assert.Equal(len(skipped), 4)                                       // Should explain each skipped installed member
+	assert.Equal(skipped[0], "held is held at 1.0.0")                   // Held member should be skipped
+	assert.Equal(skipped[1], "current is up to date (1.1.0)")           // Up-to-date member should be skipped
+
+	candidates, _ = upgradeCandidates(members, true)
+	assert.Equal(names(candidates), []string{"outdated", "mismatched", "missing"}) // Should also install required missing members
+
+	candidates, skipped = upgradeCandidates(nil, true)
+	assert.Equal(len(candidates), 0) // No members should have no candidates
+	assert.Equal(len(skipped), 0)    // No members should skip nothing
+}
@@ -0,0 +1,92 @@
+package hold
+
+import (
+	"fmt"
+
+	"github.com/candrewlee14/webman/cmd/complete"
+	"github.com/candrewlee14/webman/pkgparse"
+	"github.com/candrewlee14/webman/ui"
+	"github.com/candrewlee14/webman/utils"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// HoldCmd represents the hold command
+var HoldCmd = &cobra.Command{
+	Use:   "hold [pkg]...",
+	Short: "keep packages at their installed versions",
+	Long: `
+The "hold" subcommand keeps installed packages from being upgraded by "webman upgrade"
+and "webman group upgrade", until they are released with "webman unhold".
+Without arguments, it lists the held packages.`,
+	Example: `webman hold go
+webman hold
+webman unhold go`,
+	ValidArgsFunction: complete.InstalledPackages,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return printHeld()
+		}
+		return setHeld(args, true)
+	},
+}
+
+// UnholdCmd represents the unhold command
+var UnholdCmd = &cobra.Command{
+	Use:   "unhold [pkg]...",
+	Short: "allow held packages to be upgraded again",
+	Long: `
+The "unhold" subcommand releases packages held with "webman hold", so they are upgraded again.`,
+	Example:           `webman unhold go`,
+	ValidArgsFunction: complete.InstalledPackages,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return cmd.Help()
+		}
+		return setHeld(args, false)
+	},
+}
+
+func setHeld(pkgs []string, held bool) error {
+	for _, pkg := range pkgs {
+		if err := pkgparse.SetHeld(pkg, held); err != nil {
+			return err
+		}
+		if held {
+			fmt.Printf("Holding %s at its installed versions\n", color.CyanString(pkg))
+		} else {
+			fmt.Printf("Released %s for upgrades\n", color.CyanString(pkg))
+		}
+	}
+	if ui.IsJSONOutput() {
+		return printHeld()
+	}
+	return nil
+}
+
+// printHeld prints all held packages
+func printHeld() error {
+	held := []string{}
+	for _, pkg := range utils.InstalledPackages() {
+		isHeld, err := pkgparse.IsHeld(pkg)
+		if err != nil {
+			return err
+		}
+		if isHeld {
+			held = append(held, pkg)
+		}
+	}
+	if ui.IsJSONOutput() {
+		return ui.PrintJSON(struct {
+			Held []string `json:"held"`
+		}{held})
+	}
+	if len(held) == 0 {
+		color.HiBlack("No packages are held.")
+	}
+	for _, pkg := range held {
+		fmt.Println(color.CyanString(pkg))
+	}
+	return nil
+}
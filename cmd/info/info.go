@@ -0,0 +1,201 @@
+package info
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/candrewlee14/webman/cmd/complete"
+	"github.com/candrewlee14/webman/config"
+	"github.com/candrewlee14/webman/pkgparse"
+	"github.com/candrewlee14/webman/ui"
+	"github.com/candrewlee14/webman/utils"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// InfoCmd represents the info command
+var InfoCmd = &cobra.Command{
+	Use:   "info [pkg]",
+	Short: "show information about a package",
+	Long: `
+The "info" subcommand shows the full recipe information for a package,
+along with its installed versions and the latest available version.
+When several repos have a recipe for the package, they are all listed
+and the recipe from the highest priority repo is shown, unless one is selected with repo/pkg.`,
+	Example: `webman info go
+webman info webman/go
+webman info rg --output json`,
+	ValidArgsFunction: complete.Single(complete.Packages),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return cmd.Help()
+		}
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		if err := cfg.RefreshDueRepos(); err != nil {
+			return err
+		}
+		repo, pkg, _, err := utils.ParsePkgVer(args[0])
+		if err != nil {
+			return err
+		}
+		pkgRepos, err := config.SelectRepos(cfg.PkgRepos, repo)
+		if err != nil {
+			return err
+		}
+		pkgConf, err := pkgparse.ParsePkgConfigLocal(pkgRepos, pkg)
+		if err != nil {
+			return err
+		}
+		providers, err := pkgparse.FindPkgRepos(cfg.PkgRepos, pkg)
+		if err != nil {
+			return err
+		}
+
+		info := PkgInfo{
+			Name:        pkgConf.Title,
+			Tagline:     pkgConf.Tagline,
+			About:       pkgConf.About,
+			InfoUrl:     pkgConf.InfoUrl,
+			SourceUrl:   pkgConf.SourceUrl,
+			ReleasesUrl: pkgConf.ReleasesUrl,
+			Platforms:   pkgConf.SupportedPlatforms(),
+			Repo:        pkgConf.Repo,
+			Installed:   []InstalledVersion{},
+		}
+		for _, provider := range providers {
+			info.Repos = append(info.Repos, provider.Name)
+		}
+		for _, note := range []string{pkgConf.InstallNote, pkgConf.MyOsInfo().InstallNote} {
+			if note != "" {
+				info.InstallNotes = append(info.InstallNotes, note)
+			}
+		}
+		for _, note := range []string{pkgConf.RemoveNote, pkgConf.MyOsInfo().RemoveNote} {
+			if note != "" {
+				info.RemoveNotes = append(info.RemoveNotes, note)
+			}
+		}
+		stems, err := utils.InstalledPkgVerStems(pkg)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		for _, stem := range stems {
+			_, ver := utils.ParseStem(stem)
+			installed := InstalledVersion{Version: ver}
+			installInfo, err := pkgparse.CheckInstalled(pkg, stem)
+			if err != nil {
+				return err
+			}
+			if installInfo != nil {
+				installed.Repo = installInfo.Repo
+			}
+			info.Installed = append(info.Installed, installed)
+		}
+		using, err := pkgparse.CheckUsing(pkg)
+		if err != nil {
+			return err
+		}
+		if using != nil {
+			_, info.Using = utils.ParseStem(*using)
+		}
+		latest, err := pkgConf.GetLatestVersion()
+		if err != nil {
+			info.LatestErr = err.Error()
+		} else {
+			info.Latest = *latest
+		}
+
+		if ui.IsJSONOutput() {
+			return ui.PrintJSON(info)
+		}
+		printInfo(info)
+		return nil
+	},
+}
+
+// PkgInfo is the information shown about a package
+type PkgInfo struct {
+	Name         string                `json:"name"`
+	Tagline      string                `json:"tagline"`
+	About        string                `json:"about"`
+	InfoUrl      string                `json:"info_url,omitempty"`
+	SourceUrl    string                `json:"source_url,omitempty"`
+	ReleasesUrl  string                `json:"releases_url,omitempty"`
+	Platforms    []pkgparse.OsArchPair `json:"platforms"`
+	Repo         string                `json:"repo"`
+	Repos        []string              `json:"repos"`
+	InstallNotes []string              `json:"install_notes,omitempty"`
+	RemoveNotes  []string              `json:"remove_notes,omitempty"`
+	Installed    []InstalledVersion    `json:"installed"`
+	Using        string                `json:"using,omitempty"`
+	Latest       string                `json:"latest,omitempty"`
+	LatestErr    string                `json:"latest_error,omitempty"`
+}
+
+// InstalledVersion is an installed version of a package and the repo it was installed from
+type InstalledVersion struct {
+	Version string `json:"version"`
+	Repo    string `json:"repo,omitempty"`
+}
+
+func printInfo(info PkgInfo) {
+	fmt.Printf("%s %s %s\n", color.CyanString(info.Name), color.HiBlackString("-"), info.Tagline)
+	fmt.Println(strings.TrimSpace(info.About))
+	fmt.Println()
+	printField := func(label string, value string) {
+		if value != "" {
+			fmt.Printf("%s %s\n", color.YellowString("%-10s", label+":"), value)
+		}
+	}
+	printField("Info", info.InfoUrl)
+	printField("Source", info.SourceUrl)
+	printField("Releases", info.ReleasesUrl)
+	platforms := make([]string, len(info.Platforms))
+	for i, pair := range info.Platforms {
+		platforms[i] = pair.String()
+	}
+	printField("Platforms", strings.Join(platforms, ", "))
+	if len(info.Repos) > 1 {
+		repos := make([]string, len(info.Repos))
+		for i, repo := range info.Repos {
+			repos[i] = repo
+			if repo == info.Repo {
+				repos[i] = color.GreenString("%s (shown)", repo)
+			}
+		}
+		printField("Repos", strings.Join(repos, ", "))
+	} else {
+		printField("Repo", info.Repo)
+	}
+	if info.LatestErr != "" {
+		printField("Latest", color.RedString("unknown (%s)", info.LatestErr))
+	} else {
+		printField("Latest", color.MagentaString(info.Latest))
+	}
+	if len(info.Installed) == 0 {
+		printField("Installed", color.HiBlackString("none"))
+	} else {
+		installed := make([]string, len(info.Installed))
+		for i, ver := range info.Installed {
+			installed[i] = ver.Version
+			if ver.Repo != "" {
+				installed[i] = ver.Repo + "/" + ver.Version
+			}
+			if ver.Version == info.Using {
+				installed[i] = color.GreenString("%s (using)", installed[i])
+			}
+		}
+		printField("Installed", strings.Join(installed, ", "))
+	}
+	for _, note := range info.InstallNotes {
+		printField("Note", note)
+	}
+	for _, note := range info.RemoveNotes {
+		printField("On remove", note)
+	}
+}
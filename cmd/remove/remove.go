@@ -4,11 +4,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/candrewlee14/webman/cmd/complete"
 	"github.com/candrewlee14/webman/config"
 	"github.com/candrewlee14/webman/link"
 	"github.com/candrewlee14/webman/multiline"
 	"github.com/candrewlee14/webman/pkgparse"
+	"github.com/candrewlee14/webman/ui"
 	"github.com/candrewlee14/webman/utils"
 
 	"github.com/AlecAivazis/survey/v2"
@@ -16,14 +19,24 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var (
+	allFlag        bool
+	keepLatestFlag int
+	yesFlag        bool
+)
+
 // RemoveCmd represents the remove command
 var RemoveCmd = &cobra.Command{
-	Use:   "remove [pkg]",
+	Use:   "remove [pkg](@[ver])",
 	Short: "remove a package",
-	Long:  `The "remove" subcommand removes a prompt-selected version of a given package.`,
+	Long: `
+The "remove" subcommand removes a prompt-selected version of a given package.
+A version can be given directly, or selected with --all or --keep-latest to remove without prompting.`,
 	Example: `webman remove go
-webman remove zig
-webman remove rg`,
+webman remove go@1.21.0
+webman remove go --all --yes
+webman remove go --keep-latest 2 --yes`,
+	ValidArgsFunction: complete.Single(complete.InstalledPackages),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 1 {
 			return cmd.Help()
@@ -32,14 +45,23 @@ webman remove rg`,
 		if err != nil {
 			return err
 		}
-		pkg := args[0]
+		_, pkg, ver, err := utils.ParsePkgVer(args[0])
+		if err != nil {
+			return err
+		}
+		keepLatest := cmd.Flags().Changed("keep-latest")
+		if keepLatest && keepLatestFlag < 0 {
+			return ui.Errorf(ui.CodeInvalidArgs, "--keep-latest must not be negative")
+		}
+		if (ver != "" && (allFlag || keepLatest)) || (allFlag && keepLatest) {
+			return ui.Errorf(ui.CodeInvalidArgs, "only one of a version, --all, or --keep-latest may be given")
+		}
 
-		pkgDir := filepath.Join(utils.WebmanPkgDir, pkg)
-		dirEntries, err := os.ReadDir(pkgDir)
+		pkgVersions, err := utils.InstalledPkgVerStems(pkg)
 		if err != nil {
 			if os.IsNotExist(err) {
 				fmt.Printf("No versions of %s are currently installed.\n", color.CyanString(pkg))
-				return nil
+				return printRemoved(pkg, nil)
 			}
 			return err
 		}
@@ -53,16 +75,33 @@ webman remove rg`,
 			fmt.Printf("Not currently using any %s version\n", color.CyanString(pkg))
 		}
 
-		var pkgVersions []string
-		for _, entry := range dirEntries {
-			if entry.IsDir() {
-				pkgVersions = append(pkgVersions, entry.Name())
-			}
-		}
 		var pkgVerStems []string
-		if len(pkgVersions) == 1 {
+		needsConfirm := false
+		switch {
+		case ver != "":
+			pkgVerStem := utils.CreateStem(pkg, ver)
+			for _, installed := range pkgVersions {
+				if installed == pkgVerStem {
+					pkgVerStems = append(pkgVerStems, pkgVerStem)
+				}
+			}
+			if len(pkgVerStems) == 0 {
+				return ui.Errorf(ui.CodeNotInstalled, "%s@%s is not installed", pkg, ver)
+			}
+		case allFlag:
+			pkgVerStems = pkgVersions
+			needsConfirm = true
+		case keepLatest:
+			if len(pkgVersions) > keepLatestFlag {
+				pkgVerStems = pkgVersions[:len(pkgVersions)-keepLatestFlag]
+			}
+			needsConfirm = true
+		case len(pkgVersions) == 1:
 			pkgVerStems = append(pkgVerStems, pkgVersions[0])
-		} else {
+		default:
+			if !ui.AreInteractivePromptsEnabled() {
+				return ui.Errorf(ui.CodePromptUnavailable, "multiple versions of %s are installed; select one with %s@[ver], --all, or --keep-latest", pkg, pkg)
+			}
 			surveyPrompt := &survey.MultiSelect{
 				Message:  "Select " + color.CyanString(pkg) + " version to " + color.RedString("remove") + ":",
 				Options:  pkgVersions,
@@ -73,30 +112,73 @@ webman remove rg`,
 				return fmt.Errorf("Prompt failed %v\n", err)
 			}
 		}
-		pkgConf, err := pkgparse.ParsePkgConfigLocal(cfg.PkgRepos, pkg)
+		if len(pkgVerStems) == 0 {
+			fmt.Printf("No %s versions to remove.\n", color.CyanString(pkg))
+			return printRemoved(pkg, nil)
+		}
+		if len(pkgVerStems) == len(pkgVersions) {
+			if dependents := pkgparse.InstalledDependents(cfg.PkgRepos, pkg); len(dependents) != 0 {
+				color.Yellow("Warning: %s is a dependency of installed package(s) %s", pkg, strings.Join(dependents, ", "))
+				needsConfirm = true
+			}
+		}
+		if needsConfirm && !yesFlag {
+			if !ui.AreInteractivePromptsEnabled() {
+				return ui.Errorf(ui.CodePromptUnavailable, "refusing to remove %d %s version(s) without confirmation; pass --yes", len(pkgVerStems), pkg)
+			}
+			prompt := &survey.Confirm{
+				Message: fmt.Sprintf("Remove %s?", color.CyanString(strings.Join(pkgVerStems, ", "))),
+			}
+			confirmed := false
+			if err := survey.AskOne(prompt, &confirmed); err != nil || !confirmed {
+				color.HiBlack("No packages removed.")
+				return nil
+			}
+		}
+		pkgConf, err := InstalledPkgConf(cfg.PkgRepos, pkg)
 		if err != nil {
 			return err
 		}
-		// if we are installing all versions, remove the whole directory
+		// if we are removing all versions, remove the whole directory
 		if len(pkgVerStems) == len(pkgVersions) {
 			if _, err := RemoveAllVers(pkg, pkgConf); err != nil {
 				return err
 			}
 		} else {
 			for _, pkgVerStem := range pkgVerStems {
-				if err = RemovePkgVer(pkgVerStem, using, pkg, pkgConf); err != nil {
+				if err = RemovePkgVer(pkgVerStem, using, pkg, pkgConf, printLine); err != nil {
 					return err
 				}
 			}
 		}
 		fmt.Print(pkgConf.RemoveNotes())
 		fmt.Printf("All %d selected packages are uninstalled.\n", len(pkgVerStems))
-		return nil
+		return printRemoved(pkg, pkgVerStems)
 	},
 }
 
+// printRemoved prints the removed version stems of a package when using JSON output
+func printRemoved(pkg string, pkgVerStems []string) error {
+	if !ui.IsJSONOutput() {
+		return nil
+	}
+	if pkgVerStems == nil {
+		pkgVerStems = []string{}
+	}
+	return ui.PrintJSON(struct {
+		Package string   `json:"package"`
+		Removed []string `json:"removed"`
+	}{pkg, pkgVerStems})
+}
+
+func init() {
+	RemoveCmd.Flags().BoolVarP(&allFlag, "all", "a", false, "remove all installed versions of the package")
+	RemoveCmd.Flags().IntVar(&keepLatestFlag, "keep-latest", 0, "remove all but the given number of latest versions")
+	RemoveCmd.Flags().BoolVarP(&yesFlag, "yes", "y", false, "do not ask for confirmation")
+}
+
 // Uninstalls the binaries for a package (if they are installed)
-func UninstallBins(pkg string, pkgConf *pkgparse.PkgConfig) error {
+func UninstallBins(pkg string) error {
 	using, err := pkgparse.CheckUsing(pkg)
 	if err != nil {
 		return err
@@ -104,27 +186,14 @@ func UninstallBins(pkg string, pkgConf *pkgparse.PkgConfig) error {
 	if using == nil {
 		return nil
 	}
-	pkgVerStem := *using
-	relbinPaths, err := pkgConf.GetMyBinPaths()
-	if err != nil {
-		return err
-	}
-	_, ver := utils.ParseStem(pkgVerStem)
-	renames, err := pkgConf.GetRenames()
-	if err != nil {
+	fmt.Printf("Removing %s links ...\n", color.CyanString(pkg))
+	// links are found by their targets, so they are removed even if the recipe changed since they were made
+	if err := link.RemoveBinLinks(pkg); err != nil {
 		return err
 	}
-	_, linkPaths, err := link.GetBinPathsAndLinkPaths(pkg, ver, relbinPaths, renames)
-	if err != nil {
+	if err := link.RemoveShareLinks(pkg); err != nil {
 		return err
 	}
-	fmt.Printf("Removing %s links ...\n", color.CyanString(pkg))
-	for _, linkPath := range linkPaths {
-		err := os.Remove(linkPath)
-		if err != nil {
-			return err
-		}
-	}
 	fmt.Printf("%s%sRemoved %s links!\n", multiline.MoveUp, multiline.ClearLine, color.CyanString(pkg))
 	if err = pkgparse.RemoveUsing(pkg); err != nil {
 		return err
@@ -132,10 +201,31 @@ func UninstallBins(pkg string, pkgConf *pkgparse.PkgConfig) error {
 	return nil
 }
 
-func RemovePkgVer(pkgVerStem string, using *string, pkg string, pkgConf *pkgparse.PkgConfig) error {
+// InstalledPkgConf parses the recipe of the version of an installed package that made its links.
+// If no recipe can be found at all, the package can still be removed without its hooks and notes.
+func InstalledPkgConf(pkgRepos []*config.PkgRepo, pkg string) (*pkgparse.PkgConfig, error) {
+	pkgConf, err := pkgparse.ParseActivePkgConfig(pkgRepos, pkg)
+	if ui.CodeOf(err) == ui.CodeRecipeNotFound {
+		color.Yellow("No recipe found for %s; removing it without running hooks", pkg)
+		return &pkgparse.PkgConfig{Title: pkg}, nil
+	}
+	return pkgConf, err
+}
+
+// printLine prints a line of output to stdout
+func printLine(format string, a ...any) {
+	fmt.Printf(format+"\n", a...)
+}
+
+// RemovePkgVer removes a package version, running its pre-remove hooks with output passed to logf
+func RemovePkgVer(pkgVerStem string, using *string, pkg string, pkgConf *pkgparse.PkgConfig, logf func(format string, a ...any)) error {
+	// a failing hook shouldn't keep a package from being removed
+	if err := pkgConf.RunHooks(pkgConf.PreRemoveHooks(), pkgVerStem, logf); err != nil {
+		logf(color.RedString("Pre-remove hook failed: %v", err))
+	}
 	// if the selected pkgVerStem is being used, uninstall bins
 	if using != nil && *using == pkgVerStem {
-		if err := UninstallBins(pkg, pkgConf); err != nil {
+		if err := UninstallBins(pkg); err != nil {
 			return fmt.Errorf("Error uninstalling binaries: %v", err)
 		}
 	}
@@ -145,11 +235,11 @@ func RemovePkgVer(pkgVerStem string, using *string, pkg string, pkgConf *pkgpars
 	} else {
 		fmt.Printf("%s%sRemoved %s!\n", multiline.MoveUp, multiline.ClearLine, pkgVerStem)
 	}
-	return nil
+	return pkgparse.RemoveInstalled(pkg, pkgVerStem)
 }
 
 func RemoveAllVers(pkg string, pkgConf *pkgparse.PkgConfig) (bool, error) {
-	if err := UninstallBins(pkg, pkgConf); err != nil {
+	if err := UninstallBins(pkg); err != nil {
 		return false, err
 	}
 	pkgDir := filepath.Join(utils.WebmanPkgDir, pkg)
@@ -159,6 +249,15 @@ func RemoveAllVers(pkg string, pkgConf *pkgparse.PkgConfig) (bool, error) {
 		}
 		return false, err
 	}
+	pkgVerStems, err := utils.InstalledPkgVerStems(pkg)
+	if err != nil {
+		return false, err
+	}
+	for _, pkgVerStem := range pkgVerStems {
+		if err := pkgConf.RunHooks(pkgConf.PreRemoveHooks(), pkgVerStem, printLine); err != nil {
+			color.Red("Pre-remove hook failed for %s: %v", pkgVerStem, err)
+		}
+	}
 	if err := os.RemoveAll(pkgDir); err != nil {
 		return false, err
 	}
@@ -0,0 +1,64 @@
+package refresh
+
+import (
+	"github.com/candrewlee14/webman/config"
+	"github.com/candrewlee14/webman/ui"
+	"github.com/candrewlee14/webman/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var RefreshCmd = &cobra.Command{
+	Use:   "refresh [repo]...",
+	Short: "refresh package recipes now",
+	Long: `
+The "repo refresh" subcommand refreshes the recipes of the given package repositories,
+or of all of them, whether or not they are due for a refresh.
+Pinned repositories are only downloaded if their recipes are missing. If their pinned tag was moved
+to another commit, the refresh fails; use "webman repo update" to review and apply the change.`,
+	Example: `webman repo refresh
+webman repo refresh webman`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if utils.RecipeDirFlag != "" {
+			return ui.Errorf(ui.CodeInvalidArgs, "cannot refresh repositories when using local recipes")
+		}
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		pkgRepos := cfg.PkgRepos
+		if len(args) != 0 {
+			pkgRepos = nil
+			for _, arg := range args {
+				selected, err := config.SelectRepos(cfg.PkgRepos, arg)
+				if err != nil {
+					return ui.Errorf(ui.CodeInvalidArgs, "%v", err)
+				}
+				pkgRepos = append(pkgRepos, selected...)
+			}
+		}
+		results, refreshErr := config.RefreshRepos(pkgRepos)
+		if ui.IsJSONOutput() {
+			jsonResults := make([]RepoRefresh, len(results))
+			for i, result := range results {
+				jsonResults[i] = RepoRefresh{Name: result.Repo, Updated: result.Updated, Error: ui.NewJSONError(result.Err)}
+			}
+			if err := ui.PrintJSON(struct {
+				Repos []RepoRefresh `json:"repos"`
+			}{jsonResults}); err != nil {
+				return err
+			}
+		}
+		if refreshErr != nil {
+			return ui.Errorf(ui.CodeRefreshFailed, "%v", refreshErr)
+		}
+		return nil
+	},
+}
+
+// RepoRefresh is the machine-readable result of refreshing a package repository
+type RepoRefresh struct {
+	Name    string        `json:"name"`
+	Updated bool          `json:"updated"`
+	Error   *ui.JSONError `json:"error,omitempty"`
+}
@@ -0,0 +1,31 @@
+package repo
+
+import (
+	reporefresh "github.com/candrewlee14/webman/cmd/repo/refresh"
+	repostatus "github.com/candrewlee14/webman/cmd/repo/status"
+	repoupdate "github.com/candrewlee14/webman/cmd/repo/update"
+
+	"github.com/spf13/cobra"
+)
+
+var RepoCmd = &cobra.Command{
+	Use:   "repo",
+	Short: "manage package recipe repositories",
+	Long: `
+
+The "repo" subcommand manages the recipes fetched from package repositories.
+Repositories themselves are added and removed with "webman config".
+`,
+	Example: `
+webman repo status
+webman repo refresh
+webman repo update
+webman repo update webman --ref v1.2.0
+`,
+}
+
+func init() {
+	RepoCmd.AddCommand(reporefresh.RefreshCmd)
+	RepoCmd.AddCommand(repostatus.StatusCmd)
+	RepoCmd.AddCommand(repoupdate.UpdateCmd)
+}
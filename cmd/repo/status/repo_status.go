@@ -0,0 +1,130 @@
+package status
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/candrewlee14/webman/config"
+	"github.com/candrewlee14/webman/ui"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var StatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "show the age and revision of package recipes",
+	Long: `
+The "repo status" subcommand shows, for each package repository, which revision
+and commit its recipes were fetched from, when they were last updated and checked,
+and whether they are due for an automatic refresh.`,
+	Example: `webman repo status
+webman repo status --output json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			return cmd.Help()
+		}
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		statuses := make([]RepoStatus, 0, len(cfg.PkgRepos))
+		for _, pkgRepo := range cfg.PkgRepos {
+			status := RepoStatus{
+				Name:     pkgRepo.Name,
+				Revision: pkgRepo.Revision(),
+				Pinned:   pkgRepo.IsPinned(),
+				Priority: pkgRepo.Priority,
+			}
+			if _, err := os.Stat(pkgRepo.Path()); err == nil {
+				status.Fetched = true
+			} else if !os.IsNotExist(err) {
+				return err
+			}
+			meta, err := pkgRepo.LoadMeta()
+			if err != nil {
+				return err
+			}
+			status.Commit = meta.Commit
+			if !meta.UpdatedAt.IsZero() {
+				status.UpdatedAt = &meta.UpdatedAt
+			}
+			if !meta.CheckedAt.IsZero() {
+				status.CheckedAt = &meta.CheckedAt
+			}
+			status.Due, err = pkgRepo.ShouldRefreshRecipes(cfg.RefreshInterval)
+			if err != nil {
+				return err
+			}
+			statuses = append(statuses, status)
+		}
+		if ui.IsJSONOutput() {
+			return ui.PrintJSON(struct {
+				Repos []RepoStatus `json:"repos"`
+			}{statuses})
+		}
+		for _, status := range statuses {
+			printStatus(status)
+		}
+		return nil
+	},
+}
+
+// RepoStatus is the state of the recipes of a package repository
+type RepoStatus struct {
+	Name      string     `json:"name"`
+	Revision  string     `json:"revision"`
+	Pinned    bool       `json:"pinned"`
+	Priority  int        `json:"priority"`
+	Fetched   bool       `json:"fetched"`
+	Commit    string     `json:"commit,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	CheckedAt *time.Time `json:"checked_at,omitempty"`
+	Due       bool       `json:"refresh_due"`
+}
+
+func printStatus(status RepoStatus) {
+	revision := color.MagentaString(status.Revision)
+	if status.Pinned {
+		revision += color.HiBlackString(" (pinned)")
+	}
+	if status.Commit != "" {
+		commit := status.Commit
+		if len(commit) > 12 {
+			commit = commit[:12]
+		}
+		revision += color.HiBlackString(" @ ") + commit
+	}
+	fmt.Printf("%s %s\n", color.CyanString(status.Name), revision)
+	if !status.Fetched {
+		fmt.Printf("  %s\n", color.YellowString("recipes not fetched yet"))
+		return
+	}
+	updated := "unknown"
+	if status.UpdatedAt != nil {
+		updated = formatAge(time.Since(*status.UpdatedAt))
+	}
+	checked := "unknown"
+	if status.CheckedAt != nil {
+		checked = formatAge(time.Since(*status.CheckedAt))
+	}
+	fmt.Printf("  updated %s, checked %s\n", updated, checked)
+	if status.Due {
+		fmt.Printf("  %s\n", color.YellowString("due for refresh"))
+	}
+}
+
+// formatAge formats a duration as a rough age, like "5m ago"
+func formatAge(age time.Duration) string {
+	switch {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age.Minutes()))
+	case age < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(age.Hours()/24))
+	}
+}
@@ -0,0 +1,247 @@
+package update
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/candrewlee14/webman/config"
+	"github.com/candrewlee14/webman/pkgparse"
+	"github.com/candrewlee14/webman/ui"
+	"github.com/candrewlee14/webman/utils"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	refFlag    string
+	unpinFlag  bool
+	yesFlag    bool
+	dryRunFlag bool
+)
+
+var UpdateCmd = &cobra.Command{
+	Use:   "update [repo]...",
+	Short: "update package recipes, showing what changed",
+	Long: `
+The "repo update" subcommand fetches the latest recipes of package repositories
+and shows which recipes were added, removed or modified before applying them.
+Changes that can alter what gets downloaded, like URLs and version formats, are highlighted.
+
+A repository can be pinned to a tag or commit with --ref, after which it is only
+updated by "webman repo update" and never by the automatic refresh.`,
+	Example: `webman repo update
+webman repo update webman --dry-run
+webman repo update webman --ref v1.2.0
+webman repo update webman --unpin --yes`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if utils.RecipeDirFlag != "" {
+			return ui.Errorf(ui.CodeInvalidArgs, "cannot update repositories when using local recipes")
+		}
+		refChanged := cmd.Flags().Changed("ref")
+		if (refChanged || unpinFlag) && len(args) != 1 {
+			return ui.Errorf(ui.CodeInvalidArgs, "--ref and --unpin require exactly one repository")
+		}
+		if refChanged && unpinFlag {
+			return ui.Errorf(ui.CodeInvalidArgs, "only one of --ref or --unpin may be given")
+		}
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		pkgRepos := cfg.PkgRepos
+		if len(args) != 0 {
+			pkgRepos = nil
+			for _, arg := range args {
+				selected, err := config.SelectRepos(cfg.PkgRepos, arg)
+				if err != nil {
+					return ui.Errorf(ui.CodeInvalidArgs, "%v", err)
+				}
+				pkgRepos = append(pkgRepos, selected...)
+			}
+		}
+
+		results := make([]RepoUpdate, 0, len(pkgRepos))
+		saveConfig := false
+		failed := 0
+		for _, pkgRepo := range pkgRepos {
+			target := *pkgRepo
+			if refChanged {
+				target.Ref = refFlag
+			}
+			if unpinFlag {
+				target.Ref = ""
+			}
+			result, err := updateRepo(pkgRepo, &target)
+			if err != nil {
+				if ui.CodeOf(err) == ui.CodePromptUnavailable {
+					return err
+				}
+				color.Red("%v", err)
+				results = append(results, RepoUpdate{Name: target.Name, Revision: target.Revision(), Error: ui.NewJSONError(err)})
+				failed++
+				continue
+			}
+			results = append(results, *result)
+			if result.Applied && target.Ref != pkgRepo.Ref {
+				pkgRepo.Ref = target.Ref
+				saveConfig = true
+			}
+		}
+		if saveConfig {
+			if err := cfg.Save(); err != nil {
+				return err
+			}
+		}
+		if ui.IsJSONOutput() {
+			if err := ui.PrintJSON(struct {
+				Repos []RepoUpdate `json:"repos"`
+			}{results}); err != nil {
+				return err
+			}
+		}
+		if failed != 0 {
+			return fmt.Errorf("Unable to update %d of %d repositories", failed, len(pkgRepos))
+		}
+		return nil
+	},
+}
+
+func init() {
+	UpdateCmd.Flags().StringVar(&refFlag, "ref", "", "pin the repository to a tag or commit SHA")
+	UpdateCmd.Flags().BoolVar(&unpinFlag, "unpin", false, "follow the repository branch again")
+	UpdateCmd.Flags().BoolVarP(&yesFlag, "yes", "y", false, "apply changes without asking for confirmation")
+	UpdateCmd.Flags().BoolVarP(&dryRunFlag, "dry-run", "n", false, "only show what would change")
+}
+
+// RepoUpdate is the result of updating a single package repository
+type RepoUpdate struct {
+	Name     string             `json:"name"`
+	Revision string             `json:"revision"`
+	Applied  bool               `json:"applied"`
+	Diff     *pkgparse.RepoDiff `json:"diff,omitempty"`
+	Error    *ui.JSONError      `json:"error,omitempty"`
+}
+
+// updateRepo stages the recipes of target, shows how they differ from the current recipes of pkgRepo,
+// and applies them if confirmed
+func updateRepo(pkgRepo *config.PkgRepo, target *config.PkgRepo) (*RepoUpdate, error) {
+	color.HiBlue("Fetching package recipes for %q at %s...", target.Name, target.Revision())
+	meta, err := target.LoadMeta()
+	if err != nil {
+		return nil, err
+	}
+	stagedPath, err := target.StageRecipes(meta)
+	if err != nil {
+		return nil, ui.Errorf(ui.CodeRefreshFailed, "unable to fetch recipes for %q: %v", target.Name, err)
+	}
+	result := &RepoUpdate{Name: target.Name, Revision: target.Revision()}
+	if stagedPath == "" {
+		fmt.Printf("Recipes for %q are up to date.\n", target.Name)
+		meta.CheckedAt = time.Now()
+		return result, target.SaveMeta(meta)
+	}
+	if err := target.ValidateRecipes(stagedPath); err != nil {
+		target.DiscardRecipes(stagedPath)
+		return nil, &ui.Error{Code: ui.CodeInvalidRecipes, Err: err}
+	}
+	diff, err := pkgparse.DiffRecipes(pkgRepo.Path(), stagedPath)
+	if err != nil {
+		target.DiscardRecipes(stagedPath)
+		return nil, err
+	}
+	result.Diff = diff
+	revisionChanged := pkgRepo.Revision() != target.Revision()
+	if revisionChanged {
+		fmt.Printf("Changing %q from %s to %s\n", target.Name, color.MagentaString(pkgRepo.Revision()), color.MagentaString(target.Revision()))
+	}
+	printDiff(diff)
+	upToDate := diff.IsEmpty() && !revisionChanged
+	if upToDate {
+		fmt.Printf("Recipes for %q are up to date.\n", target.Name)
+	}
+	if dryRunFlag {
+		target.DiscardRecipes(stagedPath)
+		return result, nil
+	}
+	// with no recipe changes there is nothing to confirm, but applying records the new commit
+	if !yesFlag && !upToDate {
+		if !ui.AreInteractivePromptsEnabled() {
+			target.DiscardRecipes(stagedPath)
+			return nil, ui.Errorf(ui.CodePromptUnavailable, "refusing to update %q without confirmation; pass --yes", target.Name)
+		}
+		prompt := &survey.Confirm{
+			Message: fmt.Sprintf("Apply these changes to %s?", color.CyanString(target.Name)),
+		}
+		confirmed := false
+		if err := survey.AskOne(prompt, &confirmed); err != nil || !confirmed {
+			target.DiscardRecipes(stagedPath)
+			color.HiBlack("Recipes for %q left unchanged.", target.Name)
+			return result, nil
+		}
+	}
+	if err := pkgparse.ApplyRecipes(target, stagedPath); err != nil {
+		return nil, &ui.Error{Code: ui.CodeInvalidRecipes, Err: err}
+	}
+	meta.UpdatedAt = time.Now()
+	meta.CheckedAt = meta.UpdatedAt
+	if err := target.SaveMeta(meta); err != nil {
+		return nil, err
+	}
+	if !upToDate {
+		result.Applied = true
+		color.Green("Updated recipes for %q", target.Name)
+	}
+	return result, nil
+}
+
+func printDiff(diff *pkgparse.RepoDiff) {
+	for _, kind := range []struct {
+		name     string
+		added    []string
+		removed  []string
+		modified []pkgparse.RecipeDiff
+	}{
+		{"package", diff.AddedPkgs, diff.RemovedPkgs, diff.ModifiedPkgs},
+		{"group", diff.AddedGroups, diff.RemovedGroups, diff.ModifiedGroups},
+	} {
+		for _, name := range kind.added {
+			fmt.Printf("  %s %s %s\n", color.GreenString("+"), kind.name, color.CyanString(name))
+		}
+		for _, name := range kind.removed {
+			fmt.Printf("  %s %s %s\n", color.RedString("-"), kind.name, color.CyanString(name))
+		}
+		for _, recipe := range kind.modified {
+			fmt.Printf("  %s %s %s\n", color.YellowString("~"), kind.name, color.CyanString(recipe.Name))
+			for _, change := range recipe.Changes {
+				// download changes are shown in full so a redirected URL can't hide in a truncated value
+				if change.AffectsDownload() {
+					fmt.Printf("      %s: %s → %s\n", color.YellowString(change.Field), showValue(change.Old), showValue(change.New))
+				} else {
+					fmt.Printf("      %s: %s → %s\n", color.HiBlackString(change.Field), summarize(change.Old), summarize(change.New))
+				}
+			}
+		}
+	}
+}
+
+func showValue(value string) string {
+	if value == "" {
+		return color.HiBlackString("(none)")
+	}
+	return value
+}
+
+// summarize shortens a field value to a single line
+func summarize(value string) string {
+	if value == "" {
+		return showValue(value)
+	}
+	value = strings.Join(strings.Fields(value), " ")
+	if len(value) > 60 {
+		value = value[:57] + "..."
+	}
+	return value
+}
@@ -52,8 +52,27 @@ A cross-platform package manager for the web!
 	- created by candrewlee14
 
 `,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := ui.SetOutputFormat(outputFlag); err != nil {
+			return err
+		}
+		if utils.RefreshFlag && utils.NoRefreshFlag {
+			return ui.Errorf(ui.CodeInvalidArgs, "only one of --refresh or --no-refresh may be given")
+		}
+		if utils.AllowHooksFlag && utils.RecipeDirFlag == "" {
+			return ui.Errorf(ui.CodeInvalidArgs, "--allow-hooks only applies to --local-recipes; set allow_hooks for a repository in the config instead")
+		}
+		if ui.IsJSONOutput() {
+			multiline.ClearLine = []byte{}
+			multiline.MoveDown = []byte{}
+			multiline.MoveUp = []byte{}
+		}
+		return nil
+	},
 }
 
+var outputFlag string
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
@@ -75,7 +94,13 @@ func Execute() {
 	}
 	err := rootCmd.Execute()
 	if err != nil {
-		color.HiRed("%v", err)
+		if !ui.IsJSONOutput() {
+			color.HiRed("%v", err)
+		} else if !ui.HasPrintedJSON() {
+			ui.PrintJSON(struct {
+				Error *ui.JSONError `json:"error"`
+			}{ui.NewJSONError(err)})
+		}
 		if ansiOn {
 			fmt.Printf("%s", multiline.ShowCursor)
 		}
@@ -90,4 +115,8 @@ func init() {
 	}
 	utils.Init(homeDir)
 	rootCmd.PersistentFlags().StringVarP(&utils.RecipeDirFlag, "local-recipes", "l", "", "use given local recipe directory")
+	rootCmd.PersistentFlags().BoolVar(&utils.AllowHooksFlag, "allow-hooks", false, "run install and remove hooks of the local recipes")
+	rootCmd.PersistentFlags().StringVarP(&outputFlag, "output", "o", ui.OutputText, "output format: text or json")
+	rootCmd.PersistentFlags().BoolVar(&utils.RefreshFlag, "refresh", false, "refresh package recipes even if they are not due")
+	rootCmd.PersistentFlags().BoolVar(&utils.NoRefreshFlag, "no-refresh", false, "do not refresh package recipes, even if they are due")
 }
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/candrewlee14/webman/cmd/complete"
 	"github.com/candrewlee14/webman/config"
 	"github.com/candrewlee14/webman/pkgparse"
 	"github.com/candrewlee14/webman/utils"
@@ -28,6 +29,13 @@ webman run go@18.0.0
 webman run node@17.0.0 --version
 webman run node@17.0.0:npm --version
 webman run node:npm --version`,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		// the arguments after the package are passed to it, so complete them as files
+		if len(args) != 0 {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+		return complete.InstalledPackages(cmd, args, toComplete)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 {
 			return cmd.Help()
@@ -38,6 +46,7 @@ webman run node:npm --version`,
 }
 
 func runPackage(args []string) error {
+	var repo string
 	var pkg string
 	var ver string
 	var binName string
@@ -54,17 +63,19 @@ func runPackage(args []string) error {
 	// Version information
 	pkgVerAndBinParts := strings.Split(args[0], ":")
 	if len(pkgVerAndBinParts) == 1 {
-		pkgStr, verStr, err := utils.ParsePkgVer(args[0])
+		repoStr, pkgStr, verStr, err := utils.ParsePkgVer(args[0])
 		if err != nil {
 			return err
 		}
+		repo = repoStr
 		pkg = pkgStr
 		ver = verStr
 	} else if len(pkgVerAndBinParts) == 2 {
-		pkgStr, verStr, err := utils.ParsePkgVer(pkgVerAndBinParts[0])
+		repoStr, pkgStr, verStr, err := utils.ParsePkgVer(pkgVerAndBinParts[0])
 		if err != nil {
 			return err
 		}
+		repo = repoStr
 		pkg = pkgStr
 		ver = verStr
 		binName = pkgVerAndBinParts[1]
@@ -77,16 +88,6 @@ func runPackage(args []string) error {
 		argsApp = args[1:]
 	}
 
-	pkgConf, err := pkgparse.ParsePkgConfigLocal(cfg.PkgRepos, pkg)
-	if err != nil {
-		return err
-	}
-
-	binPaths, err := pkgConf.GetMyBinPaths()
-	if err != nil {
-		return err
-	}
-
 	// Is custom version
 	var pkgDirName string
 	if ver != "" {
@@ -108,6 +109,27 @@ func runPackage(args []string) error {
 		}
 		return fmt.Errorf("Error when accessing package version folder: %v\n", err)
 	}
+
+	var pkgConf *pkgparse.PkgConfig
+	if repo != "" {
+		pkgRepos, err := config.SelectRepos(cfg.PkgRepos, repo)
+		if err != nil {
+			return err
+		}
+		pkgConf, err = pkgparse.ParsePkgConfigLocal(pkgRepos, pkg)
+		if err != nil {
+			return err
+		}
+	} else {
+		pkgConf, err = pkgparse.ParseInstalledPkgConfig(cfg.PkgRepos, pkg, pkgDirName)
+		if err != nil {
+			return err
+		}
+	}
+	binPaths, err := pkgConf.GetMyBinPaths()
+	if err != nil {
+		return err
+	}
 	var truePkgBinPath *string
 	for _, binPath := range binPaths {
 		pkgBinDirOrFile = filepath.Join(pkgRunFolder, binPath)
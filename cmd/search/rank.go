@@ -0,0 +1,96 @@
+package search
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/candrewlee14/webman/pkgparse"
+)
+
+// Scores for where a query term matches, from most to least relevant
+const (
+	scoreTitleExact       = 1000
+	scoreTitlePrefix      = 500
+	scoreTitleContains    = 300
+	scoreTaglineContains  = 100
+	scoreAboutContains    = 50
+	scoreTitleSubsequence = 20
+)
+
+// rankPkgs fuzzy-matches each whitespace-separated query term against package titles, taglines and descriptions.
+// Packages that match every term are returned from most to least relevant.
+func rankPkgs(pkgInfos []pkgparse.PkgIndexEntry, query string) []pkgparse.PkgIndexEntry {
+	terms := strings.Fields(strings.ToLower(query))
+	var scores []int
+	var matches []pkgparse.PkgIndexEntry
+pkgLoop:
+	for _, pkgInfo := range pkgInfos {
+		total := 0
+		for _, term := range terms {
+			score := scoreTerm(pkgInfo, term)
+			if score == 0 {
+				continue pkgLoop
+			}
+			total += score
+		}
+		scores = append(scores, total)
+		matches = append(matches, pkgInfo)
+	}
+	sort.Stable(rankedPkgs{matches, scores})
+	return matches
+}
+
+// rankedPkgs sorts packages by descending score, then by name
+type rankedPkgs struct {
+	pkgs   []pkgparse.PkgIndexEntry
+	scores []int
+}
+
+func (r rankedPkgs) Len() int {
+	return len(r.pkgs)
+}
+
+func (r rankedPkgs) Less(i, j int) bool {
+	if r.scores[i] != r.scores[j] {
+		return r.scores[i] > r.scores[j]
+	}
+	return r.pkgs[i].Title < r.pkgs[j].Title
+}
+
+func (r rankedPkgs) Swap(i, j int) {
+	r.pkgs[i], r.pkgs[j] = r.pkgs[j], r.pkgs[i]
+	r.scores[i], r.scores[j] = r.scores[j], r.scores[i]
+}
+
+func scoreTerm(pkgInfo pkgparse.PkgIndexEntry, term string) int {
+	title := strings.ToLower(pkgInfo.Title)
+	tagline := strings.ToLower(pkgInfo.Tagline)
+	about := strings.ToLower(pkgInfo.About)
+	switch {
+	case title == term:
+		return scoreTitleExact
+	case strings.HasPrefix(title, term):
+		return scoreTitlePrefix
+	case strings.Contains(title, term):
+		return scoreTitleContains
+	case strings.Contains(tagline, term):
+		return scoreTaglineContains
+	case strings.Contains(about, term):
+		return scoreAboutContains
+	case isSubsequence(term, title):
+		return scoreTitleSubsequence
+	}
+	return 0
+}
+
+// isSubsequence checks if all characters of sub appear in s in order
+func isSubsequence(sub string, s string) bool {
+	subRunes := []rune(sub)
+	i := 0
+	for _, ch := range s {
+		if i < len(subRunes) && ch == subRunes[i] {
+			i++
+		}
+	}
+	return i == len(subRunes)
+}
@@ -0,0 +1,35 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/candrewlee14/webman/pkgparse"
+
+	"github.com/matryer/is"
+)
+
+func TestRankPkgs(t *testing.T) {
+	assert := is.New(t)
+
+	pkgInfos := []pkgparse.PkgIndexEntry{
+		{Title: "bat", Tagline: "A cat clone with wings", About: "Syntax highlighting for a large number of languages"},
+		{Title: "rg", Tagline: "ripgrep: a faster grep", About: "Recursively searches directories for a regex pattern"},
+		{Title: "grex", Tagline: "Generate regular expressions", About: "Generates regex from user-provided test cases"},
+		{Title: "jq", Tagline: "Command-line JSON processor", About: "Like sed for JSON data"},
+	}
+
+	matches := rankPkgs(pkgInfos, "grep")
+	assert.Equal(len(matches), 1)        // Only rg should match grep
+	assert.Equal(matches[0].Title, "rg") // rg should match by tagline
+
+	matches = rankPkgs(pkgInfos, "regex")
+	assert.Equal(len(matches), 2)          // grex and rg mention regex
+	assert.Equal(matches[0].Title, "grex") // Equally relevant matches should be sorted by name
+
+	matches = rankPkgs(pkgInfos, "json sed")
+	assert.Equal(len(matches), 1)        // Every term must match
+	assert.Equal(matches[0].Title, "jq") // jq matches both terms
+
+	matches = rankPkgs(pkgInfos, "")
+	assert.Equal(len(matches), len(pkgInfos)) // Empty query should match everything
+}
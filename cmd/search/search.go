@@ -1,7 +1,6 @@
 package search
 
 import (
-	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -13,6 +12,7 @@ import (
 	"github.com/candrewlee14/webman/config"
 	"github.com/candrewlee14/webman/multiline"
 	"github.com/candrewlee14/webman/pkgparse"
+	"github.com/candrewlee14/webman/ui"
 	"github.com/candrewlee14/webman/utils"
 
 	"github.com/AlecAivazis/survey/v2"
@@ -21,54 +21,33 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var doRefresh bool
-
 var SearchCmd = &cobra.Command{
-	Use:   "search",
+	Use:   "search [query]",
 	Short: "search for a package",
 	Long: `
-The "search" subcommand starts an interactive window to find and display info about a package`,
-	Example: `webman search`,
+The "search" subcommand starts an interactive window to find and display info about a package.
+Given a query, or when not running interactively, it prints packages from all repositories
+whose name, tagline or description fuzzy-match the query, most relevant first.`,
+	Example: `webman search
+webman search grep
+webman search grep --output json`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if len(args) != 0 {
+		if len(args) > 1 {
 			return cmd.Help()
 		}
 		cfg, err := config.Load()
 		if err != nil {
 			return err
 		}
-		// if local recipe flag is not set
-		if utils.RecipeDirFlag == "" {
-			// only refresh if not using local
-			for _, pkgRepo := range cfg.PkgRepos {
-				shouldRefresh, err := pkgRepo.ShouldRefreshRecipes(cfg.RefreshInterval)
-				if err != nil {
-					return err
-				}
-				if shouldRefresh || doRefresh {
-					color.HiBlue("Refreshing package recipes for %q...", pkgRepo.Name)
-					if err = pkgRepo.RefreshRecipes(); err != nil {
-						color.Red("%v", err)
-					}
-				}
-			}
+		if err := cfg.RefreshDueRepos(); err != nil {
+			return err
 		}
-		pkgInfos := make([]*pkgparse.PkgConfig, 0)
-		for _, pkgRepo := range cfg.PkgRepos {
-			files, err := os.ReadDir(pkgRepo.PackagePath())
-			if err != nil {
-				return err
-			}
-			for _, file := range files {
-				pkg := strings.Split(file.Name(), utils.PkgRecipeExt)[0]
-				pkgInfo, err := pkgparse.ParsePkgConfigPath(pkgRepo.Path(), pkg)
-				if err != nil {
-					return err
-				}
-				pkgInfos = append(pkgInfos, pkgInfo)
-			}
+		recipeIndex, err := pkgparse.LoadIndex(cfg.PkgRepos)
+		if err != nil {
+			return err
 		}
-		sort.Slice(pkgInfos, func(i, j int) bool {
+		pkgInfos := recipeIndex.Pkgs()
+		sort.SliceStable(pkgInfos, func(i, j int) bool {
 			return pkgInfos[i].Title < pkgInfos[j].Title
 		})
 
@@ -77,6 +56,13 @@ The "search" subcommand starts an interactive window to find and display info ab
 		for _, i := range installed {
 			installedSet[i] = struct{}{}
 		}
+		if len(args) == 1 || !ui.AreInteractivePromptsEnabled() {
+			var query string
+			if len(args) == 1 {
+				query = args[0]
+			}
+			return printMatches(rankPkgs(pkgInfos, query), installedSet, len(cfg.PkgRepos) > 1)
+		}
 		idx, err := fuzzyfinder.Find(
 			pkgInfos,
 			func(i int) string {
@@ -123,17 +109,57 @@ The "search" subcommand starts an interactive window to find and display info ab
 		var wg sync.WaitGroup
 		ml := multiline.New(1, os.Stdout)
 		wg.Add(1)
-		pkg := add.InstallPkg(cfg.PkgRepos, pkgName, 0, 1, &wg, &ml, false, false)
-		if pkg == nil {
-			return errors.New("failed to install pkg")
+		pkg, err := add.InstallPkg(cfg.PkgRepos, pkgName, 0, 1, &wg, &ml, false, false)
+		if err != nil {
+			return err
 		}
 		fmt.Print(pkg.PkgConf.InstallNotes())
 		return nil
 	},
 }
 
-func init() {
-	SearchCmd.Flags().BoolVar(&doRefresh, "refresh", false, "force refresh of package recipes")
+// PkgJSONMatch is the machine-readable form of a search match
+type PkgJSONMatch struct {
+	Name      string `json:"name"`
+	Tagline   string `json:"tagline"`
+	About     string `json:"about"`
+	Repo      string `json:"repo"`
+	Installed bool   `json:"installed"`
+}
+
+func printMatches(matches []pkgparse.PkgIndexEntry, installedSet map[string]struct{}, showRepo bool) error {
+	if ui.IsJSONOutput() {
+		jsonMatches := make([]PkgJSONMatch, 0, len(matches))
+		for _, match := range matches {
+			_, installed := installedSet[match.Title]
+			jsonMatches = append(jsonMatches, PkgJSONMatch{
+				Name:      match.Title,
+				Tagline:   match.Tagline,
+				About:     match.About,
+				Repo:      match.Repo,
+				Installed: installed,
+			})
+		}
+		return ui.PrintJSON(struct {
+			Packages []PkgJSONMatch `json:"packages"`
+		}{jsonMatches})
+	}
+	if len(matches) == 0 {
+		color.HiBlack("No packages found.")
+		return nil
+	}
+	for _, match := range matches {
+		pre := "   "
+		if _, ok := installedSet[match.Title]; ok {
+			pre = "✅ "
+		}
+		title := color.CyanString(match.Title)
+		if showRepo {
+			title = color.HiBlackString(match.Repo+"/") + title
+		}
+		fmt.Println(pre + title + color.HiBlackString(" - ") + match.Tagline)
+	}
+	return nil
 }
 
 func wrapText(text string, width int) string {
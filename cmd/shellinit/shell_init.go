@@ -0,0 +1,195 @@
+package shellinit
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/candrewlee14/webman/cmd/env"
+	"github.com/candrewlee14/webman/link"
+	"github.com/candrewlee14/webman/ui"
+	"github.com/candrewlee14/webman/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var noCompletionsFlag bool
+
+// ShellInitCmd represents the shell-init command
+var ShellInitCmd = &cobra.Command{
+	Use:   "shell-init [shell]",
+	Short: "print the shell integration script",
+	Long: `
+The "shell-init" subcommand prints a script that sets up webman in a shell (bash, zsh, fish or powershell).
+It puts ~/.webman/bin on the PATH, exports the environment variables of the packages in use,
+applies the package versions of ` + utils.ProjectFileName + ` project files when changing directories,
+adds the completions and man pages linked from packages in ~/.webman/share,
+and loads completions for package names, installed versions and group names.
+The shell is detected from $SHELL when not given.
+
+A project file lists the package versions to use in a directory and its subdirectories:
+
+  packages:
+    - go@1.22.x
+    - node@20.11.1`,
+	Example: `# ~/.bashrc
+eval "$(webman shell-init bash)"
+# ~/.zshrc, after compinit
+eval "$(webman shell-init zsh)"
+# ~/.config/fish/config.fish
+webman shell-init fish | source
+# PowerShell $PROFILE
+webman shell-init powershell | Out-String | Invoke-Expression`,
+	ValidArgs: env.Shells,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 1 {
+			return cmd.Help()
+		}
+		shell := env.DetectShell()
+		if len(args) == 1 {
+			shell = args[0]
+		}
+		script, err := Script(cmd.Root(), shell, !noCompletionsFlag)
+		if err != nil {
+			return err
+		}
+		fmt.Print(script)
+		return nil
+	},
+}
+
+func init() {
+	ShellInitCmd.Flags().BoolVar(&noCompletionsFlag, "no-completions", false, "leave out shell completions")
+}
+
+// Script returns the shell integration script for a shell
+func Script(root *cobra.Command, shell string, completions bool) (string, error) {
+	var sb strings.Builder
+	var err error
+	binDir := quote(shell, utils.WebmanBinDir)
+	manDir := quote(shell, link.ManDir())
+	switch shell {
+	case "bash", "zsh":
+		fmt.Fprintf(&sb, `case ":$PATH:" in
+  *:%[1]s:*) ;;
+  *) export PATH=%[1]s:"$PATH" ;;
+esac
+_webman_hook() {
+  local previous_exit_status=$?
+  if [ "$_WEBMAN_PWD" != "$PWD" ]; then
+    _WEBMAN_PWD="$PWD"
+    eval "$(command webman env --shell %[2]s --project)"
+  fi
+  return $previous_exit_status
+}
+webman() {
+  command webman "$@"
+  local exit_status=$?
+  # switching versions may change the environment variables
+  _WEBMAN_PWD=
+  return $exit_status
+}
+case ":${MANPATH:-}:" in
+  *:%[3]s:*) ;;
+  *) export MANPATH=%[3]s:"${MANPATH:-}" ;;
+esac
+`, binDir, shell, manDir)
+		if shell == "bash" {
+			sb.WriteString(`if [[ ";${PROMPT_COMMAND:-};" != *";_webman_hook;"* ]]; then
+  PROMPT_COMMAND="_webman_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+fi
+`)
+			if completions {
+				// bash-completion looks up package completions in $XDG_DATA_DIRS
+				fmt.Fprintf(&sb, `case ":${XDG_DATA_DIRS:-}:" in
+  *:%[1]s:*) ;;
+  *) export XDG_DATA_DIRS=%[1]s:"${XDG_DATA_DIRS:-/usr/local/share:/usr/share}" ;;
+esac
+`, quote(shell, utils.WebmanShareDir))
+				err = root.GenBashCompletionV2(&sb, true)
+			}
+		} else {
+			sb.WriteString(`autoload -Uz add-zsh-hook
+add-zsh-hook precmd _webman_hook
+`)
+			if completions {
+				// completions need compinit, which the user's zshrc loads first
+				zshDir := quote(shell, link.CompletionDir("zsh"))
+				fmt.Fprintf(&sb, `fpath=(%[1]s $fpath)
+if (( $+functions[compdef] )); then
+  for _webman_f in %[1]s/_*(N); do
+    autoload -Uz ${_webman_f:t}
+    compdef ${_webman_f:t} ${${_webman_f:t}#_}
+  done
+  unset _webman_f
+`, zshDir)
+				err = root.GenZshCompletion(&sb)
+				sb.WriteString("\nfi\n")
+			}
+		}
+	case "fish":
+		fmt.Fprintf(&sb, `contains -- %[1]s $PATH; or set -gx PATH %[1]s $PATH
+function _webman_hook --on-variable PWD
+  command webman env --shell fish --project | source
+end
+function webman
+  command webman $argv
+  set -l exit_status $status
+  # switching versions may change the environment variables
+  _webman_hook
+  return $exit_status
+end
+_webman_hook
+# an empty entry keeps the default man pages
+set -q MANPATH; or set -gx MANPATH ''
+contains -- %[2]s $MANPATH; or set -gx MANPATH %[2]s $MANPATH
+`, binDir, manDir)
+		if completions {
+			fmt.Fprintf(&sb, "contains -- %[1]s $fish_complete_path; or set -g fish_complete_path %[1]s $fish_complete_path\n",
+				quote(shell, link.CompletionDir("fish")))
+			err = root.GenFishCompletion(&sb, true)
+		}
+	case "powershell", "pwsh":
+		fmt.Fprintf(&sb, `if (-not (($env:PATH -split [IO.Path]::PathSeparator) -contains %[1]s)) {
+  $env:PATH = %[1]s + [IO.Path]::PathSeparator + $env:PATH
+}
+$global:_webmanExe = (Get-Command webman -CommandType Application | Select-Object -First 1).Source
+$global:_webmanPwd = $null
+$global:_webmanPrompt = $function:prompt
+function global:prompt {
+  if ($global:_webmanPwd -ne $PWD.Path) {
+    $global:_webmanPwd = $PWD.Path
+    & $global:_webmanExe env --shell powershell --project | Out-String | Invoke-Expression
+  }
+  & $global:_webmanPrompt
+}
+function global:webman {
+  & $global:_webmanExe @args
+  # switching versions may change the environment variables
+  $global:_webmanPwd = $null
+}
+`, binDir)
+		if completions {
+			fmt.Fprintf(&sb, "Get-ChildItem -Path %s -Filter *.ps1 -ErrorAction SilentlyContinue | ForEach-Object { . $_.FullName }\n",
+				quote(shell, link.CompletionDir("powershell")))
+			err = root.GenPowerShellCompletionWithDesc(&sb)
+		}
+	default:
+		return "", ui.Errorf(ui.CodeInvalidArgs, "unsupported shell %q, expected one of %s", shell, strings.Join(env.Shells, ", "))
+	}
+	if err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// quote quotes a string for a shell
+func quote(shell string, s string) string {
+	switch shell {
+	case "fish":
+		return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+	case "powershell", "pwsh":
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	default:
+		return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+	}
+}
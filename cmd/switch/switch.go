@@ -3,11 +3,14 @@ package switchcmd
 import (
 	"fmt"
 	"os"
-	"path/filepath"
+	"strings"
 
+	"github.com/candrewlee14/webman/cmd/complete"
+	"github.com/candrewlee14/webman/cmd/env"
 	"github.com/candrewlee14/webman/config"
 	"github.com/candrewlee14/webman/link"
 	"github.com/candrewlee14/webman/pkgparse"
+	"github.com/candrewlee14/webman/ui"
 	"github.com/candrewlee14/webman/utils"
 
 	"github.com/AlecAivazis/survey/v2"
@@ -17,13 +20,33 @@ import (
 
 // SwitchCmd represents the remove command
 var SwitchCmd = &cobra.Command{
-	Use:   "switch [pkg]",
+	Use:   "switch [pkg](@[ver])",
 	Short: "switch to a specific version of a package",
-	Long:  `The "switch" subcommand changes path to a prompt-selected version of a given package.`,
+	Long: `
+The "switch" subcommand changes path to a prompt-selected version of a given package.
+A version can be given directly, as go@1.22.1 or go 1.22.1, to switch without prompting.`,
 	Example: `webman switch go
+webman switch go@1.22.1
+webman switch go 1.22.1
 webman switch zig
 webman switch rg`,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		switch len(args) {
+		case 0:
+			return complete.InstalledPackages(cmd, args, toComplete)
+		case 1:
+			if strings.Contains(args[0], "@") {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return complete.InstalledVersions(args[0]), cobra.ShellCompDirectiveNoFileComp
+		default:
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 2 && !strings.Contains(args[0], "@") {
+			args = []string{args[0] + "@" + args[1]}
+		}
 		if len(args) != 1 {
 			return cmd.Help()
 		}
@@ -31,12 +54,14 @@ webman switch rg`,
 		if err != nil {
 			return err
 		}
-		pkg := args[0]
-		pkgDir := filepath.Join(utils.WebmanPkgDir, pkg)
-		dirEntries, err := os.ReadDir(pkgDir)
+		_, pkg, ver, err := utils.ParsePkgVer(args[0])
+		if err != nil {
+			return err
+		}
+		pkgVersions, err := utils.InstalledPkgVerStems(pkg)
 		if err != nil {
 			if os.IsNotExist(err) {
-				return fmt.Errorf("No versions of %s are currently installed.\n", pkg)
+				return ui.Errorf(ui.CodeNotInstalled, "No versions of %s are currently installed.", pkg)
 			}
 			return err
 		}
@@ -51,24 +76,30 @@ webman switch rg`,
 			fmt.Printf("Not currently using any %s version\n", color.CyanString(pkg))
 		}
 
-		var pkgVersions []string
-		for _, entry := range dirEntries {
-			if entry.IsDir() {
-				pkgVersions = append(pkgVersions, entry.Name())
-			}
-		}
-		pkgConf, err := pkgparse.ParsePkgConfigLocal(cfg.PkgRepos, pkg)
-		if err != nil {
-			return err
-		}
 		var pkgVerStem string
-		if len(pkgVersions) == 1 {
+		if ver != "" {
+			for _, installed := range pkgVersions {
+				if installed == utils.CreateStem(pkg, ver) {
+					pkgVerStem = installed
+				}
+			}
+			if pkgVerStem == "" {
+				return ui.Errorf(ui.CodeNotInstalled, "%s@%s is not installed", pkg, ver)
+			}
+			if using != nil && *using == pkgVerStem {
+				fmt.Printf("Already using %s.\n", pkgVerStem)
+				return printUsing(pkg, pkgVerStem)
+			}
+		} else if len(pkgVersions) == 1 {
 			pkgVerStem = pkgVersions[0]
 			if using != nil && *using == pkgVerStem {
 				fmt.Printf("Only one version of %s installed, which is already in use.\n", pkg)
-				return nil
+				return printUsing(pkg, pkgVerStem)
 			}
 		} else {
+			if !ui.AreInteractivePromptsEnabled() {
+				return ui.Errorf(ui.CodePromptUnavailable, "multiple versions of %s are installed; select one with %s@[ver]", pkg, pkg)
+			}
 			surveyPrompt := &survey.Select{
 				Message: "Select " + color.CyanString(pkg) + " version to switch to use:",
 				Options: pkgVersions,
@@ -78,11 +109,15 @@ webman switch rg`,
 				return fmt.Errorf("Prompt failed %v\n", err)
 			}
 		}
+		pkgConf, err := pkgparse.ParseInstalledPkgConfig(cfg.PkgRepos, pkg, pkgVerStem)
+		if err != nil {
+			return err
+		}
 		relbinPaths, err := pkgConf.GetMyBinPaths()
 		if err != nil {
 			return err
 		}
-		_, ver := utils.ParseStem(pkgVerStem)
+		_, ver = utils.ParseStem(pkgVerStem)
 		renames, err := pkgConf.GetRenames()
 		if err != nil {
 			return err
@@ -94,8 +129,32 @@ webman switch rg`,
 		if !madeLinks {
 			return fmt.Errorf("Unable to create all links")
 		}
+		if err = pkgConf.WriteUsingEnv(pkgVerStem); err != nil {
+			return err
+		}
+		shareLinks, err := link.CreateShareLinks(pkgConf, pkgVerStem)
+		if err != nil {
+			color.Yellow("Failed linking completions and man pages: %v", err)
+		}
 		fmt.Printf("Created links for %s\n", pkgVerStem)
+		if len(pkgConf.EnvVars(pkgVerStem)) != 0 {
+			fmt.Println("Updated environment variables; run", color.CyanString(env.ApplyHint()), "to apply them to this shell")
+		}
+		if hint := link.ShareHint(shareLinks); hint != "" {
+			color.HiBlack("%s", hint)
+		}
 		color.Green("Successfully switched, %s now using %s\n", pkg, color.CyanString(pkgVerStem))
-		return nil
+		return printUsing(pkg, pkgVerStem)
 	},
 }
+
+// printUsing prints the version stem a package is using when using JSON output
+func printUsing(pkg string, pkgVerStem string) error {
+	if !ui.IsJSONOutput() {
+		return nil
+	}
+	return ui.PrintJSON(struct {
+		Package string `json:"package"`
+		Using   string `json:"using"`
+	}{pkg, pkgVerStem})
+}
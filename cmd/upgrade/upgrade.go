@@ -4,27 +4,30 @@ import (
 	"os"
 
 	"github.com/candrewlee14/webman/cmd/add"
+	"github.com/candrewlee14/webman/cmd/complete"
 	"github.com/candrewlee14/webman/config"
+	"github.com/candrewlee14/webman/pkgparse"
+	"github.com/candrewlee14/webman/ui"
 	"github.com/candrewlee14/webman/utils"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
-var (
-	doRefresh bool
-)
+var ignoreHoldsFlag bool
 
 // upgradeCmd represents the upgrade command
 var UpgradeCmd = &cobra.Command{
 	Use:   "upgrade [pkgs...]",
 	Short: "upgrade packages",
 	Long: `
-The "upgrade" subcommand adds the latest version of packages, switches to use that version, and removes the old.`,
+The "upgrade" subcommand adds the latest version of packages, switches to use that version, and removes the old.
+Packages held with "webman hold" are skipped unless --ignore-holds is given.`,
 	Example: `webman upgrade go
 webman upgrade go@18.0.0
 webman upgrade go zig rg
 webman upgrade go@18.0.0 zig@9.1.0 rg@13.0.0`,
+	ValidArgsFunction: complete.UpgradablePackages,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 {
 			return cmd.Help()
@@ -34,34 +37,40 @@ webman upgrade go@18.0.0 zig@9.1.0 rg@13.0.0`,
 			return err
 		}
 		defer os.RemoveAll(utils.WebmanTmpDir)
-		// if local recipe flag is not set
-		if utils.RecipeDirFlag == "" {
-			// only refresh if not using local
-			for _, pkgRepo := range cfg.PkgRepos {
-				shouldRefresh, err := pkgRepo.ShouldRefreshRecipes(cfg.RefreshInterval)
-				if err != nil {
-					return err
-				}
-				if shouldRefresh || doRefresh {
-					color.HiBlue("Refreshing package recipes for %q...", pkgRepo.Name)
-					if err = pkgRepo.RefreshRecipes(); err != nil {
-						color.Red("%v", err)
-					}
-				}
+		if err := cfg.RefreshDueRepos(); err != nil {
+			return err
+		}
+		var toUpgrade []string
+		for _, arg := range args {
+			_, pkg, _, err := utils.ParsePkgVer(arg)
+			if err != nil {
+				return ui.Errorf(ui.CodeInvalidArgs, "%v", err)
+			}
+			held, err := pkgparse.IsHeld(pkg)
+			if err != nil {
+				return err
 			}
+			if held && !ignoreHoldsFlag {
+				color.HiBlack("%s is held; release it with \"webman unhold %s\" to upgrade it", pkg, pkg)
+				continue
+			}
+			toUpgrade = append(toUpgrade, arg)
+		}
+		if len(toUpgrade) == 0 {
+			return add.PrintResults(nil, nil)
 		}
-		pkgs := add.InstallAllPkgs(cfg.PkgRepos, args, true, true)
-		if len(args) != len(pkgs) {
-			color.Red("Not all packages installed successfully")
+		pkgs, errs := add.InstallAllPkgs(cfg.PkgRepos, toUpgrade, true, true)
+		if err := add.PrintResults(pkgs, errs); err != nil {
+			return err
 		}
-		if len(args) == len(pkgs) {
-			color.Green("All %d packages are installed!", len(args))
+		if len(errs) != 0 {
+			return ui.Errorf(ui.CodeInstallFailed, "Not all packages installed successfully")
 		}
-
+		color.Green("All %d packages are installed!", len(pkgs))
 		return nil
 	},
 }
 
 func init() {
-	UpgradeCmd.Flags().BoolVar(&doRefresh, "refresh", false, "force refresh of package recipes")
+	UpgradeCmd.Flags().BoolVar(&ignoreHoldsFlag, "ignore-holds", false, "upgrade held packages too")
 }
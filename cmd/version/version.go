@@ -3,6 +3,8 @@ package version
 import (
 	"runtime/debug"
 
+	"github.com/candrewlee14/webman/ui"
+
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
@@ -25,6 +27,15 @@ The "version" subcommand displays the latest webman version.`,
 		if len(args) != 0 {
 			return cmd.Help()
 		}
+		if ui.IsJSONOutput() {
+			return ui.PrintJSON(struct {
+				Version string `json:"version"`
+				Commit  string `json:"commit"`
+				Dirty   bool   `json:"dirty"`
+				Date    string `json:"date"`
+				BuiltBy string `json:"built_by"`
+			}{Version, Commit, dirty, Date, BuiltBy})
+		}
 		color.Cyan("webman (%s)", Version)
 		verLen := 8
 		if len(Commit) < 8 {
@@ -0,0 +1,129 @@
+package which
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/candrewlee14/webman/cmd/complete"
+	"github.com/candrewlee14/webman/link"
+	"github.com/candrewlee14/webman/pkgparse"
+	"github.com/candrewlee14/webman/ui"
+	"github.com/candrewlee14/webman/utils"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// WhichCmd represents the which command
+var WhichCmd = &cobra.Command{
+	Use:   "which [bin]",
+	Short: "show which package provides a binary",
+	Long: `
+The "which" subcommand resolves a binary in ~/.webman/bin to the package version that provides it,
+and reports other binaries with the same name that run instead because they come first on the PATH.`,
+	Example: `webman which rg
+webman which npm --output json`,
+	ValidArgsFunction: complete.Single(func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		entries, err := os.ReadDir(utils.WebmanBinDir)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		var bins []string
+		for _, entry := range entries {
+			bins = append(bins, entry.Name())
+		}
+		return bins, cobra.ShellCompDirectiveNoFileComp
+	}),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return cmd.Help()
+		}
+		bin := args[0]
+		owner, err := link.ResolveLink(bin)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return ui.Errorf(ui.CodeNotInstalled, "no binary named %s is installed by webman", bin)
+			}
+			return err
+		}
+		shadows := link.Shadows(bin)
+		if ui.IsJSONOutput() {
+			if shadows == nil {
+				shadows = []string{}
+			}
+			return ui.PrintJSON(struct {
+				Binary string `json:"binary"`
+				*link.LinkOwner
+				ShadowedBy []string `json:"shadowed_by"`
+			}{bin, owner, shadows})
+		}
+		fmt.Printf("%s is provided by %s@%s\n", color.CyanString(bin), color.CyanString(owner.Pkg), color.MagentaString(owner.Version))
+		fmt.Printf("%s %s\n", color.YellowString("%-8s", "Link:"), owner.Link)
+		fmt.Printf("%s %s\n", color.YellowString("%-8s", "Target:"), owner.Target)
+		if _, err := os.Stat(owner.Target); err != nil {
+			color.Red("The link is broken: %v", err)
+		}
+		if len(shadows) != 0 {
+			color.Yellow("Shadowed by %s, which comes first on the PATH and runs instead", shadows[0])
+			for _, shadow := range shadows[1:] {
+				color.Yellow("Also shadowed by %s", shadow)
+			}
+		}
+		return nil
+	},
+}
+
+// WhereCmd represents the where command
+var WhereCmd = &cobra.Command{
+	Use:   "where [pkg](@[ver])",
+	Short: "show where a package is installed",
+	Long: `
+The "where" subcommand prints the directory of the package version in use,
+or of the newest installed version matching a given version.`,
+	Example: `webman where go
+webman where go@1.22.1
+cd "$(webman where go)"`,
+	ValidArgsFunction: complete.Single(complete.InstalledPackages),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return cmd.Help()
+		}
+		_, pkg, ver, err := utils.ParsePkgVer(args[0])
+		if err != nil {
+			return ui.Errorf(ui.CodeInvalidArgs, "%v", err)
+		}
+		var pkgVerStem string
+		if ver != "" {
+			pkgVerStem = pkgparse.NewestInstalledVersion(pkg, ver)
+			if pkgVerStem == "" {
+				return ui.Errorf(ui.CodeNotInstalled, "%s@%s is not installed", pkg, ver)
+			}
+		} else {
+			using, err := pkgparse.CheckUsing(pkg)
+			if err != nil {
+				return err
+			}
+			if using == nil {
+				if stems, err := utils.InstalledPkgVerStems(pkg); err != nil || len(stems) == 0 {
+					return ui.Errorf(ui.CodeNotInstalled, "%s is not installed", pkg)
+				}
+				return ui.Errorf(ui.CodeNotInstalled, "not using any version of %s; give one with %s@[ver]", pkg, pkg)
+			}
+			pkgVerStem = *using
+		}
+		path := filepath.Join(utils.WebmanPkgDir, pkg, pkgVerStem)
+		if ui.IsJSONOutput() {
+			_, ver := utils.ParseStem(pkgVerStem)
+			return ui.PrintJSON(struct {
+				Package string `json:"package"`
+				Version string `json:"version"`
+				Path    string `json:"path"`
+			}{pkg, ver, path})
+		}
+		fmt.Println(path)
+		return nil
+	},
+}
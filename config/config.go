@@ -9,6 +9,8 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/candrewlee14/webman/schema"
@@ -42,8 +44,62 @@ type PkgRepo struct {
 	User   string      `yaml:"user"`
 	Repo   string      `yaml:"repo"`
 	Branch string      `yaml:"branch"`
+	// Ref pins the repo to a tag or commit SHA instead of following Branch
+	Ref string `yaml:"ref,omitempty"`
 
 	GiteaURL string `yaml:"gitea_url"`
+
+	// Priority orders repos when several define the same package, highest first
+	Priority int `yaml:"priority,omitempty"`
+	// AllowHooks lets recipes from this repo run install and remove hooks
+	AllowHooks bool `yaml:"allow_hooks,omitempty"`
+}
+
+// LocalRepoName is the repo name of groups defined in ~/.webman/groups, so no configured repo can use it
+const LocalRepoName = "local"
+
+// ValidateRepoName checks that a repo name isn't reserved
+func ValidateRepoName(name string) error {
+	if name == LocalRepoName {
+		return fmt.Errorf("repository name %q is reserved for local groups", name)
+	}
+	return nil
+}
+
+// ByPriority returns the repos in the order they are searched, highest priority first.
+// Repos with equal priority keep the order they are listed in, and the given slice is left as it is.
+func ByPriority(pkgRepos []*PkgRepo) []*PkgRepo {
+	sorted := append([]*PkgRepo{}, pkgRepos...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority > sorted[j].Priority
+	})
+	return sorted
+}
+
+// SelectRepos returns the repo with the given name, or all repos by priority if no name is given
+func SelectRepos(pkgRepos []*PkgRepo, name string) ([]*PkgRepo, error) {
+	if name == "" {
+		return ByPriority(pkgRepos), nil
+	}
+	for _, pkgRepo := range pkgRepos {
+		if pkgRepo.Name == name {
+			return []*PkgRepo{pkgRepo}, nil
+		}
+	}
+	return nil, fmt.Errorf("no package repository named %q", name)
+}
+
+// Revision is the git ref recipes are fetched from, the pinned ref if there is one and otherwise the branch
+func (p PkgRepo) Revision() string {
+	if p.Ref != "" {
+		return p.Ref
+	}
+	return p.Branch
+}
+
+// IsPinned determines whether a PkgRepo is pinned to a tag or commit
+func (p PkgRepo) IsPinned() bool {
+	return p.Ref != ""
 }
 
 // Validate checks if a PkgRepo is valid
@@ -51,9 +107,9 @@ func (p PkgRepo) Validate() (bool, error) {
 	var url string
 	switch p.Type {
 	case PkgRepoTypeGitHub:
-		url = fmt.Sprintf("https://api.github.com/repos/%s/%s/contents/pkgs?ref=%s", p.User, p.Repo, p.Branch)
+		url = fmt.Sprintf("https://api.github.com/repos/%s/%s/contents/pkgs?ref=%s", p.User, p.Repo, p.Revision())
 	case PkgRepoTypeGitea:
-		url = fmt.Sprintf("%s/api/v1/repos/%s/%s/contents/pkgs?ref=%s", p.GiteaURL, p.User, p.Repo, p.Branch)
+		url = fmt.Sprintf("%s/api/v1/repos/%s/%s/contents/pkgs?ref=%s", p.GiteaURL, p.User, p.Repo, p.Revision())
 	default:
 		return false, errors.New("unknown package repository type")
 	}
@@ -94,7 +150,8 @@ func (p PkgRepo) GroupPath() string {
 	return filepath.Join(p.Path(), "groups")
 }
 
-// ShouldRefreshRecipes determines whether a PkgRepo needs to be refreshed
+// ShouldRefreshRecipes determines whether a PkgRepo needs to be refreshed.
+// Pinned repos never change, so they are only fetched if missing.
 func (p PkgRepo) ShouldRefreshRecipes(refreshInterval time.Duration) (bool, error) {
 	fi, err := os.Stat(p.Path())
 	if err != nil {
@@ -104,62 +161,274 @@ func (p PkgRepo) ShouldRefreshRecipes(refreshInterval time.Duration) (bool, erro
 		}
 		return false, err
 	}
-	return time.Since(fi.ModTime()) > refreshInterval, nil
+	meta, err := p.LoadMeta()
+	if err != nil {
+		return false, err
+	}
+	if meta.Revision != "" && meta.Revision != p.Revision() {
+		return true, nil
+	}
+	if p.IsPinned() {
+		return false, nil
+	}
+	lastChecked := meta.CheckedAt
+	if lastChecked.IsZero() {
+		lastChecked = fi.ModTime()
+	}
+	return time.Since(lastChecked) > refreshInterval, nil
 }
 
-// RefreshRecipes refreshes the recipes for a PkgRepo
+// RefreshRecipes refreshes the recipes for a PkgRepo, skipping the download if they haven't changed.
+// The current recipes are kept if the new ones can't be fetched or fail validation.
 func (p PkgRepo) RefreshRecipes() error {
+	_, err := p.refreshRecipes()
+	return err
+}
+
+// refreshRecipes refreshes the recipes for a PkgRepo, returning whether they were updated
+func (p PkgRepo) refreshRecipes() (bool, error) {
+	meta, err := p.LoadMeta()
+	if err != nil {
+		return false, err
+	}
+	// pinned recipes are only fetched when missing or repinned, so a moved tag can't change them unreviewed
+	if _, statErr := os.Stat(p.Path()); statErr == nil && p.IsPinned() && (meta.Revision == "" || meta.Revision == p.Revision()) {
+		return false, p.checkPinnedCommit(meta)
+	}
+	stagedPath, err := p.StageRecipes(meta)
+	if err != nil {
+		return false, err
+	}
+	updated := stagedPath != ""
+	if updated {
+		if err := p.ApplyRecipes(stagedPath); err != nil {
+			return false, err
+		}
+		meta.UpdatedAt = time.Now()
+	}
+	meta.CheckedAt = time.Now()
+	return updated, p.SaveMeta(meta)
+}
+
+// checkPinnedCommit checks that the pinned revision of a PkgRepo still points at the commit its recipes were fetched from
+func (p PkgRepo) checkPinnedCommit(meta *RepoMeta) error {
+	commit, err := p.LatestCommit(meta)
+	if err != nil {
+		return err
+	}
+	if meta.Commit != "" && commit != meta.Commit {
+		return fmt.Errorf("pinned revision %s now points at commit %s instead of %s; review the change with \"webman repo update %s\"",
+			p.Revision(), shortCommit(commit), shortCommit(meta.Commit), p.Name)
+	}
+	meta.CheckedAt = time.Now()
+	return p.SaveMeta(meta)
+}
+
+// shortCommit abbreviates a commit SHA for messages
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
+
+// RecipeError is a recipe that failed validation
+type RecipeError struct {
+	// Path is the path of the recipe relative to the repository root
+	Path string
+	Err  error
+}
+
+// RecipeValidationError is returned when fetched recipes fail validation
+type RecipeValidationError struct {
+	Repo   string
+	Errors []RecipeError
+}
+
+// Error implements error
+func (e *RecipeValidationError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d recipe(s) for %q failed validation, keeping current recipes:", len(e.Errors), e.Repo)
+	for _, recipeErr := range e.Errors {
+		fmt.Fprintf(&sb, "\n  %s: %v", recipeErr.Path, recipeErr.Err)
+	}
+	return sb.String()
+}
+
+// ValidateRecipes lints every package and group recipe staged by StageRecipes against the schemas
+func (p PkgRepo) ValidateRecipes(stagedPath string) error {
+	if _, err := os.Stat(filepath.Join(stagedPath, "pkgs")); err != nil {
+		return &RecipeValidationError{Repo: p.Name, Errors: []RecipeError{{Path: "pkgs", Err: err}}}
+	}
+	var recipeErrs []RecipeError
+	for _, dir := range []struct {
+		name string
+		ext  string
+		lint func(io.Reader) error
+	}{
+		{"pkgs", utils.PkgRecipeExt, schema.LintRecipe},
+		{"groups", utils.GroupRecipeExt, schema.LintGroup},
+	} {
+		entries, err := os.ReadDir(filepath.Join(stagedPath, dir.name))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		for _, entry := range entries {
+			if !strings.HasSuffix(entry.Name(), dir.ext) {
+				continue
+			}
+			relPath := filepath.Join(dir.name, entry.Name())
+			data, err := os.ReadFile(filepath.Join(stagedPath, relPath))
+			if err != nil {
+				return err
+			}
+			if err := dir.lint(bytes.NewReader(data)); err != nil {
+				recipeErrs = append(recipeErrs, RecipeError{Path: relPath, Err: err})
+			}
+		}
+	}
+	if len(recipeErrs) != 0 {
+		return &RecipeValidationError{Repo: p.Name, Errors: recipeErrs}
+	}
+	return nil
+}
+
+// StageRecipes downloads the recipes for a PkgRepo into a new temporary directory,
+// leaving the current recipes in place. It returns the path of the staged recipes.
+//
+// If meta is given, the download is skipped when the latest commit or the recipe archive
+// is unchanged since meta was recorded, and an empty path is returned.
+// Meta is updated to describe the staged recipes.
+func (p PkgRepo) StageRecipes(meta *RepoMeta) (string, error) {
+	revision := p.Revision()
+	_, statErr := os.Stat(p.Path())
+	// only skip the download if the current recipes exist and were fetched from the same revision
+	canSkip := meta != nil && statErr == nil && meta.Revision == p.Revision()
+	var commit string
+	if meta != nil {
+		// the commit lookup is only an optimization, so fall back to the archive if it fails
+		if latest, err := p.LatestCommit(meta); err == nil {
+			commit = latest
+			if canSkip && commit == meta.Commit {
+				return "", nil
+			}
+			// download the exact commit so the recipes match the recorded SHA
+			revision = commit
+		}
+	}
 	var url string
 	switch p.Type {
 	case PkgRepoTypeGitHub:
-		url = fmt.Sprintf("https://github.com/%s/%s/archive/refs/heads/%s.tar.gz", p.User, p.Repo, p.Branch)
+		if revision == p.Branch && !p.IsPinned() {
+			url = fmt.Sprintf("https://github.com/%s/%s/archive/refs/heads/%s.tar.gz", p.User, p.Repo, p.Branch)
+		} else {
+			url = fmt.Sprintf("https://github.com/%s/%s/archive/%s.tar.gz", p.User, p.Repo, revision)
+		}
 	case PkgRepoTypeGitea:
-		url = fmt.Sprintf("%s/api/v1/repos/%s/%s/archive/%s.tar.gz", p.GiteaURL, p.User, p.Repo, p.Branch)
+		url = fmt.Sprintf("%s/api/v1/repos/%s/%s/archive/%s.tar.gz", p.GiteaURL, p.User, p.Repo, revision)
 	default:
-		return errors.New("unknown package repository type")
+		return "", errors.New("unknown package repository type")
 	}
 
-	r, err := http.Get(url)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
-		return err
+		return "", err
+	}
+	if canSkip && commit == "" {
+		if meta.ETag != "" {
+			req.Header.Set("If-None-Match", meta.ETag)
+		}
+		if meta.LastModified != "" {
+			req.Header.Set("If-Modified-Since", meta.LastModified)
+		}
+	}
+	r, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
 	}
 	defer r.Body.Close()
 
+	if canSkip && r.StatusCode == http.StatusNotModified {
+		return "", nil
+	}
 	if !(r.StatusCode >= 200 && r.StatusCode < 300) {
-		return fmt.Errorf("Bad HTTP Response: %s", r.Status)
+		return "", fmt.Errorf("Bad HTTP Response: %s", r.Status)
 	}
-
-	if err = os.RemoveAll(p.Path()); err != nil {
-		return err
+	if meta != nil {
+		meta.Revision = p.Revision()
+		meta.Commit = commit
+		meta.ETag = r.Header.Get("ETag")
+		meta.LastModified = r.Header.Get("Last-Modified")
 	}
+
 	if err = os.MkdirAll(utils.WebmanTmpDir, os.ModePerm); err != nil {
-		return err
+		return "", err
 	}
 	tmpZipFile, err := os.CreateTemp(utils.WebmanTmpDir, "recipes-*.tar.gz")
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer os.RemoveAll(tmpZipFile.Name())
+	defer tmpZipFile.Close()
 	if _, err = io.Copy(tmpZipFile, r.Body); err != nil {
-		return err
+		return "", err
 	}
 
-	tmpRecipeDir := filepath.Join(utils.WebmanTmpDir, "recipes")
+	tmpRecipeDir, err := os.MkdirTemp(utils.WebmanTmpDir, "recipes-"+p.Name+"-")
+	if err != nil {
+		return "", err
+	}
 	if err = archiver.Unarchive(tmpZipFile.Name(), tmpRecipeDir); err != nil {
-		return err
+		os.RemoveAll(tmpRecipeDir)
+		return "", err
 	}
 	fdir, err := os.ReadDir(tmpRecipeDir)
 	if err != nil {
-		return err
+		os.RemoveAll(tmpRecipeDir)
+		return "", err
 	}
 	if len(fdir) != 1 {
-		return fmt.Errorf("expected unzipped refresh to have a single root folder")
+		os.RemoveAll(tmpRecipeDir)
+		return "", fmt.Errorf("expected unzipped refresh to have a single root folder")
 	}
-	innerTmpFolder := filepath.Join(tmpRecipeDir, fdir[0].Name())
-	if err = os.Rename(innerTmpFolder, p.Path()); err != nil {
+	return filepath.Join(tmpRecipeDir, fdir[0].Name()), nil
+}
+
+// DiscardRecipes removes recipes staged by StageRecipes without applying them
+func (p PkgRepo) DiscardRecipes(stagedPath string) error {
+	return os.RemoveAll(filepath.Dir(stagedPath))
+}
+
+// ApplyRecipes validates recipes staged by StageRecipes and swaps them in for the current recipes of a PkgRepo.
+// If validation or the swap fails, the current recipes are left in place.
+func (p PkgRepo) ApplyRecipes(stagedPath string) error {
+	defer p.DiscardRecipes(stagedPath)
+	if err := p.ValidateRecipes(stagedPath); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(utils.WebmanRecipeDir, os.ModePerm); err != nil {
+		return err
+	}
+	// move the current recipes next to the staged ones so they are cleaned up together
+	previousPath := filepath.Join(filepath.Dir(stagedPath), "previous")
+	hasPrevious := true
+	if err := os.Rename(p.Path(), previousPath); err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		hasPrevious = false
+	}
+	if err := os.Rename(stagedPath, p.Path()); err != nil {
+		if hasPrevious {
+			if restoreErr := os.Rename(previousPath, p.Path()); restoreErr != nil {
+				return fmt.Errorf("%v, and unable to restore previous recipes: %v", err, restoreErr)
+			}
+		}
 		return err
 	}
-
 	return nil
 }
 
@@ -173,6 +442,22 @@ func (c *Config) Save() error {
 	return yaml.NewEncoder(fi).Encode(c)
 }
 
+// RemoveRepo removes a package repository from the Config along with its fetched recipes.
+// The Config still has to be saved.
+func (c *Config) RemoveRepo(name string) error {
+	for idx, pkgRepo := range c.PkgRepos {
+		if pkgRepo.Name != name {
+			continue
+		}
+		c.PkgRepos = append(c.PkgRepos[:idx], c.PkgRepos[idx+1:]...)
+		if err := os.RemoveAll(pkgRepo.Path()); err != nil {
+			return err
+		}
+		return os.RemoveAll(pkgRepo.MetaPath())
+	}
+	return fmt.Errorf("no package repository named %q", name)
+}
+
 // Load loads the Webman Config
 func Load() (*Config, error) {
 	if utils.RecipeDirFlag != "" {
@@ -181,7 +466,7 @@ func Load() (*Config, error) {
 		return &Config{
 			RefreshInterval: 0,
 			PkgRepos: []*PkgRepo{
-				{Name: "."},
+				{Name: ".", AllowHooks: utils.AllowHooksFlag},
 			},
 		}, nil
 	}
@@ -210,11 +495,13 @@ func Load() (*Config, error) {
 		return nil, err
 	}
 	for _, pkgRepo := range cfg.PkgRepos {
+		if err := ValidateRepoName(pkgRepo.Name); err != nil {
+			return nil, fmt.Errorf("%v; rename it in %q", err, utils.WebmanConfig)
+		}
 		if pkgRepo.Branch == "" {
 			pkgRepo.Branch = "main"
 		}
 	}
-
 	return &cfg, nil
 }
 
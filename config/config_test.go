@@ -0,0 +1,302 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/candrewlee14/webman/utils"
+
+	"github.com/matryer/is"
+	"github.com/mholt/archiver/v3"
+)
+
+var validRecipe = []byte(`tagline: A test package
+about: Used for testing recipe refreshes
+base_download_url: https://example.com/[VER]/
+filename_format: foo-[OS]-[ARCH]
+latest_strategy: github-release
+git_user: example
+git_repo: foo
+os_map:
+  linux:
+    name: linux
+    is_raw_binary: true
+arch_map:
+  amd64: x86_64
+`)
+
+func TestApplyRecipes(t *testing.T) {
+	assert := is.New(t)
+
+	tmp := t.TempDir()
+	utils.Init(tmp)
+	pkgRepo := PkgRepo{Name: "test"}
+	stage := func(recipe []byte) string {
+		stagedPath := filepath.Join(t.TempDir(), "test-main")
+		assert.NoErr(os.MkdirAll(filepath.Join(stagedPath, "pkgs"), os.ModePerm))                                    // Should create staged pkgs dir
+		assert.NoErr(os.WriteFile(filepath.Join(stagedPath, "pkgs", "foo"+utils.PkgRecipeExt), recipe, os.ModePerm)) // Should write staged recipe
+		return stagedPath
+	}
+
+	assert.NoErr(pkgRepo.ApplyRecipes(stage(validRecipe))) // Should apply valid recipes
+	_, err := os.Stat(filepath.Join(pkgRepo.PackagePath(), "foo"+utils.PkgRecipeExt))
+	assert.NoErr(err) // Should have applied recipe
+
+	err = pkgRepo.ApplyRecipes(stage([]byte("tagline: 5\n")))
+	var validationErr *RecipeValidationError
+	assert.True(errors.As(err, &validationErr)) // Should fail validation
+	assert.Equal(len(validationErr.Errors), 1)  // Should report the invalid recipe
+	data, err := os.ReadFile(filepath.Join(pkgRepo.PackagePath(), "foo"+utils.PkgRecipeExt))
+	assert.NoErr(err)               // Should keep current recipes
+	assert.Equal(data, validRecipe) // Should keep current recipe contents
+}
+
+// newRecipeArchive creates a recipe archive with a valid foo recipe
+func newRecipeArchive(t *testing.T) string {
+	assert := is.New(t)
+
+	srcDir := filepath.Join(t.TempDir(), "pkgs-main")
+	assert.NoErr(os.MkdirAll(filepath.Join(srcDir, "pkgs"), os.ModePerm))                                         // Should create source pkgs dir
+	assert.NoErr(os.WriteFile(filepath.Join(srcDir, "pkgs", "foo"+utils.PkgRecipeExt), validRecipe, os.ModePerm)) // Should write source recipe
+	archivePath := filepath.Join(t.TempDir(), "main.tar.gz")
+	assert.NoErr(archiver.NewTarGz().Archive([]string{srcDir}, archivePath)) // Should create recipe archive
+	return archivePath
+}
+
+// newRecipeServer serves a Gitea API whose repos are at commit abc123 with the given recipe archive,
+// except for repos of the user "bad", which don't exist. It counts the archive downloads.
+func newRecipeServer(t *testing.T, archivePath string) (*httptest.Server, *int) {
+	archiveHits := new(int)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "/bad/"):
+			http.NotFound(w, r)
+		case strings.HasSuffix(r.URL.Path, "/commits"):
+			fmt.Fprint(w, `[{"sha": "abc123"}]`)
+		case strings.HasSuffix(r.URL.Path, "/archive/abc123.tar.gz"):
+			*archiveHits++
+			http.ServeFile(w, r, archivePath)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, archiveHits
+}
+
+func TestRefreshRecipesSkipsUnchanged(t *testing.T) {
+	assert := is.New(t)
+
+	tmp := t.TempDir()
+	utils.Init(tmp)
+	srv, archiveHits := newRecipeServer(t, newRecipeArchive(t))
+	pkgRepo := PkgRepo{Name: "test", Type: PkgRepoTypeGitea, User: "u", Repo: "r", Branch: "main", GiteaURL: srv.URL}
+
+	assert.NoErr(pkgRepo.RefreshRecipes()) // Should fetch recipes
+	assert.NoErr(pkgRepo.RefreshRecipes()) // Should check recipes again
+	assert.Equal(*archiveHits, 1)          // Should only download the unchanged commit once
+	meta, err := pkgRepo.LoadMeta()
+	assert.NoErr(err)                     // Should load metadata
+	assert.Equal(meta.Commit, "abc123")   // Should record the commit
+	assert.Equal(meta.Revision, "main")   // Should record the revision
+	assert.True(!meta.CheckedAt.IsZero()) // Should record when it was checked
+	_, err = os.Stat(filepath.Join(pkgRepo.PackagePath(), "foo"+utils.PkgRecipeExt))
+	assert.NoErr(err) // Should have applied recipe
+}
+
+func TestLoadKeepsRepoOrder(t *testing.T) {
+	assert := is.New(t)
+
+	tmp := t.TempDir()
+	utils.Init(tmp)
+	cfgData := []byte(`refresh_interval: 24h
+pkg_repos:
+  - name: low
+    type: github
+    user: u
+    repo: low
+    branch: main
+  - name: high
+    type: github
+    user: u
+    repo: high
+    branch: main
+    priority: 10
+`)
+	assert.NoErr(os.WriteFile(utils.WebmanConfig, cfgData, os.ModePerm)) // Should write config
+
+	cfg, err := Load()
+	assert.NoErr(err)                                      // Should load config
+	assert.Equal(cfg.PkgRepos[0].Name, "low")              // Should keep the listed order
+	assert.Equal(ByPriority(cfg.PkgRepos)[0].Name, "high") // Should search the highest priority first
+	assert.Equal(cfg.PkgRepos[0].Name, "low")              // Sorting should not change the config
+
+	assert.NoErr(cfg.Save()) // Should save config
+	cfg, err = Load()
+	assert.NoErr(err)                         // Should load saved config
+	assert.Equal(cfg.PkgRepos[0].Name, "low") // Saving should keep the listed order
+	repos, err := SelectRepos(cfg.PkgRepos, "")
+	assert.NoErr(err)                   // Should select all repos
+	assert.Equal(repos[0].Name, "high") // All repos should be selected by priority
+}
+
+func TestRefreshRepos(t *testing.T) {
+	assert := is.New(t)
+
+	utils.Init(t.TempDir())
+	srv, archiveHits := newRecipeServer(t, newRecipeArchive(t))
+	good := &PkgRepo{Name: "good", Type: PkgRepoTypeGitea, User: "u", Repo: "r", Branch: "main", GiteaURL: srv.URL}
+	bad := &PkgRepo{Name: "bad", Type: PkgRepoTypeGitea, User: "bad", Repo: "r", Branch: "main", GiteaURL: srv.URL}
+
+	results, err := RefreshRepos([]*PkgRepo{good, bad})
+	assert.True(err != nil)                             // Failing repo should be an error
+	assert.True(strings.Contains(err.Error(), "bad"))   // Error should name the failing repo
+	assert.True(!strings.Contains(err.Error(), "good")) // Error should not name the refreshed repo
+	assert.Equal(results[0].Repo, "good")               // Results should be in repo order
+	assert.NoErr(results[0].Err)                        // Good repo should refresh
+	assert.True(results[0].Updated)                     // Good repo should be updated
+	assert.True(results[1].Err != nil)                  // Bad repo should fail
+
+	results, err = RefreshRepos([]*PkgRepo{good})
+	assert.NoErr(err)                // Should refresh again
+	assert.True(!results[0].Updated) // Unchanged repo should not be updated
+
+	cfg := &Config{RefreshInterval: time.Hour, PkgRepos: []*PkgRepo{good}}
+	meta, err := good.LoadMeta()
+	assert.NoErr(err) // Should load metadata
+	checkedAt := meta.CheckedAt
+	assert.NoErr(cfg.RefreshDueRepos()) // Should refresh due repos
+	meta, err = good.LoadMeta()
+	assert.NoErr(err)                       // Should load metadata
+	assert.Equal(meta.CheckedAt, checkedAt) // Recently checked repo should not be due
+
+	cfg.RefreshInterval = 0
+	utils.NoRefreshFlag = true
+	assert.NoErr(cfg.RefreshDueRepos()) // Should skip refreshing
+	utils.NoRefreshFlag = false
+	meta, err = good.LoadMeta()
+	assert.NoErr(err)                       // Should load metadata
+	assert.Equal(meta.CheckedAt, checkedAt) // Nothing should be refreshed with --no-refresh
+
+	assert.NoErr(cfg.RefreshDueRepos()) // Should refresh due repos
+	meta, err = good.LoadMeta()
+	assert.NoErr(err)                            // Should load metadata
+	assert.True(meta.CheckedAt.After(checkedAt)) // Repo past the refresh interval should be checked again
+	assert.Equal(*archiveHits, 1)                // Unchanged commit should not be downloaded again
+}
+
+func TestLoadRejectsLocalRepo(t *testing.T) {
+	assert := is.New(t)
+
+	utils.Init(t.TempDir())
+	assert.True(ValidateRepoName(LocalRepoName) != nil) // Local group repo name should be reserved
+	assert.NoErr(ValidateRepoName("webman"))            // Other names should be allowed
+
+	cfgData := []byte(`refresh_interval: 24h
+pkg_repos:
+  - name: local
+    type: github
+    user: u
+    repo: r
+    branch: main
+`)
+	assert.NoErr(os.WriteFile(utils.WebmanConfig, cfgData, os.ModePerm)) // Should write config
+	_, err := Load()
+	assert.True(err != nil)                                // Repo named local should be rejected
+	assert.True(strings.Contains(err.Error(), "reserved")) // Error should say why
+}
+
+func TestRefreshPinnedRepo(t *testing.T) {
+	assert := is.New(t)
+
+	utils.Init(t.TempDir())
+	archivePath := newRecipeArchive(t)
+	sha := "abc123"
+	archiveHits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/commits"):
+			fmt.Fprintf(w, `[{"sha": %q}]`, sha)
+		case strings.HasSuffix(r.URL.Path, "/archive/"+sha+".tar.gz"):
+			archiveHits++
+			http.ServeFile(w, r, archivePath)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	pkgRepo := &PkgRepo{Name: "pinned", Type: PkgRepoTypeGitea, User: "u", Repo: "r", Branch: "main", Ref: "v1", GiteaURL: srv.URL}
+
+	_, err := RefreshRepos([]*PkgRepo{pkgRepo})
+	assert.NoErr(err)            // Missing pinned recipes should be fetched
+	assert.Equal(archiveHits, 1) // Should download the pinned commit
+
+	results, err := RefreshRepos([]*PkgRepo{pkgRepo})
+	assert.NoErr(err)                // Unchanged pinned repo should refresh
+	assert.True(!results[0].Updated) // Unchanged pinned repo should not be updated
+
+	sha = "def456"
+	results, err = RefreshRepos([]*PkgRepo{pkgRepo})
+	assert.True(err != nil)                                              // Moved pinned tag should fail the refresh
+	assert.True(strings.Contains(results[0].Err.Error(), "repo update")) // Error should point to repo update
+	assert.Equal(archiveHits, 1)                                         // Moved pinned tag should not be downloaded
+	meta, err := pkgRepo.LoadMeta()
+	assert.NoErr(err)                   // Should load metadata
+	assert.Equal(meta.Commit, "abc123") // Recipes should stay at the reviewed commit
+
+	utils.RefreshFlag = true
+	defer func() { utils.RefreshFlag = false }()
+	cfg := &Config{RefreshInterval: time.Hour, PkgRepos: []*PkgRepo{pkgRepo}}
+	assert.NoErr(cfg.RefreshDueRepos()) // Forced refresh should report failures without returning them
+	assert.Equal(archiveHits, 1)        // Forced refresh should not download the moved tag
+
+	pkgRepo.Ref = "v2"
+	_, err = RefreshRepos([]*PkgRepo{pkgRepo})
+	assert.NoErr(err)            // Repinned repo should be fetched
+	assert.Equal(archiveHits, 2) // Should download the new pinned commit
+}
+
+func TestLoadLocalRecipesHooks(t *testing.T) {
+	assert := is.New(t)
+
+	utils.Init(t.TempDir())
+	utils.RecipeDirFlag = t.TempDir()
+	defer func() { utils.RecipeDirFlag, utils.AllowHooksFlag = "", false }()
+
+	cfg, err := Load()
+	assert.NoErr(err)                        // Should load local recipes
+	assert.True(!cfg.PkgRepos[0].AllowHooks) // Local recipes should not run hooks by default
+
+	utils.AllowHooksFlag = true
+	cfg, err = Load()
+	assert.NoErr(err)                       // Should load local recipes
+	assert.True(cfg.PkgRepos[0].AllowHooks) // Local recipes should run hooks with --allow-hooks
+}
+
+func TestRemoveRepo(t *testing.T) {
+	assert := is.New(t)
+
+	utils.Init(t.TempDir())
+	webman := &PkgRepo{Name: "webman"}
+	other := &PkgRepo{Name: "other"}
+	cfg := &Config{PkgRepos: []*PkgRepo{webman, other}}
+	assert.NoErr(os.MkdirAll(other.PackagePath(), os.ModePerm)) // Should create recipes dir
+	assert.NoErr(other.SaveMeta(&RepoMeta{Commit: "abc123"}))   // Should write repo meta
+
+	assert.NoErr(cfg.RemoveRepo("other")) // Should remove repo
+	assert.Equal(len(cfg.PkgRepos), 1)    // Repo should be removed from the config
+	assert.Equal(cfg.PkgRepos[0], webman) // Other repos should be kept
+	_, err := os.Stat(other.Path())
+	assert.True(os.IsNotExist(err)) // Recipes should be removed
+	_, err = os.Stat(other.MetaPath())
+	assert.True(os.IsNotExist(err)) // Repo meta should be removed
+
+	assert.True(cfg.RemoveRepo("missing") != nil) // Should not remove an unknown repo
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/candrewlee14/webman/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RepoMeta is what is recorded about the recipes of a PkgRepo, used to skip refreshes when nothing changed
+type RepoMeta struct {
+	// CheckedAt is when the repository was last checked for changes
+	CheckedAt time.Time `yaml:"checked_at"`
+	// UpdatedAt is when the recipes were last downloaded
+	UpdatedAt time.Time `yaml:"updated_at"`
+	// Revision is the branch or pinned ref the recipes were fetched from
+	Revision string `yaml:"revision"`
+	// Commit is the commit SHA the recipes were fetched from, if known
+	Commit string `yaml:"commit,omitempty"`
+	// CommitETag is the ETag of the latest commit response
+	CommitETag string `yaml:"commit_etag,omitempty"`
+	// ETag and LastModified are from the recipe archive response
+	ETag         string `yaml:"etag,omitempty"`
+	LastModified string `yaml:"last_modified,omitempty"`
+}
+
+// MetaPath is the filepath to the metadata of a given PkgRepo
+func (p PkgRepo) MetaPath() string {
+	return filepath.Join(utils.WebmanRecipeDir, p.Name+utils.RepoMetaExt)
+}
+
+// LoadMeta loads the metadata of a PkgRepo, which is empty if it has never been refreshed
+func (p PkgRepo) LoadMeta() (*RepoMeta, error) {
+	var meta RepoMeta
+	data, err := os.ReadFile(p.MetaPath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &meta, nil
+		}
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, &meta); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+// SaveMeta saves the metadata of a PkgRepo
+func (p PkgRepo) SaveMeta(meta *RepoMeta) error {
+	data, err := yaml.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(utils.WebmanRecipeDir, os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(p.MetaPath(), data, 0o644)
+}
+
+// LatestCommit asks the repository host for the commit SHA of the PkgRepo revision.
+// The request is conditional on the last response recorded in meta, which is updated.
+func (p PkgRepo) LatestCommit(meta *RepoMeta) (string, error) {
+	var url string
+	switch p.Type {
+	case PkgRepoTypeGitHub:
+		url = fmt.Sprintf("https://api.github.com/repos/%s/%s/commits/%s", p.User, p.Repo, p.Revision())
+	case PkgRepoTypeGitea:
+		url = fmt.Sprintf("%s/api/v1/repos/%s/%s/commits?sha=%s&limit=1&stat=false", p.GiteaURL, p.User, p.Repo, p.Revision())
+	default:
+		return "", errors.New("unknown package repository type")
+	}
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	if p.Type == PkgRepoTypeGitHub {
+		req.Header.Set("Accept", "application/vnd.github.sha")
+	}
+	// a matching ETag only means the commit is unchanged if it is the commit we recorded
+	if meta.CommitETag != "" && meta.Commit != "" && meta.Revision == p.Revision() {
+		req.Header.Set("If-None-Match", meta.CommitETag)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return meta.Commit, nil
+	case http.StatusOK:
+	default:
+		return "", fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	var commit string
+	switch p.Type {
+	case PkgRepoTypeGitHub:
+		commit = strings.TrimSpace(string(body))
+	case PkgRepoTypeGitea:
+		var commits []struct {
+			Sha string `json:"sha"`
+		}
+		if err := json.Unmarshal(body, &commits); err != nil {
+			return "", err
+		}
+		if len(commits) != 0 {
+			commit = commits[0].Sha
+		}
+	}
+	if commit == "" {
+		return "", errors.New("no commit found")
+	}
+	meta.CommitETag = resp.Header.Get("ETag")
+	return commit, nil
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/candrewlee14/webman/multiline"
+	"github.com/candrewlee14/webman/utils"
+
+	"github.com/fatih/color"
+)
+
+// RefreshResult is the outcome of refreshing the recipes of a package repository
+type RefreshResult struct {
+	Repo    string
+	Updated bool
+	Err     error
+}
+
+// RefreshRepos refreshes the recipes of package repositories in parallel, with a line of progress per repo.
+// Repos that fail keep their current recipes, and are named in the returned error.
+func RefreshRepos(pkgRepos []*PkgRepo) ([]RefreshResult, error) {
+	results := make([]RefreshResult, len(pkgRepos))
+	if len(pkgRepos) == 0 {
+		return results, nil
+	}
+	var wg sync.WaitGroup
+	ml := multiline.New(len(pkgRepos), os.Stdout)
+	wg.Add(len(pkgRepos))
+	for i, pkgRepo := range pkgRepos {
+		i := i
+		pkgRepo := pkgRepo
+		go func() {
+			defer wg.Done()
+			ml.SetPrefix(i, color.CyanString(pkgRepo.Name)+": ")
+			done := make(chan bool)
+			ml.PrintUntilDone(i, "Refreshing package recipes", done, 50)
+			updated, err := pkgRepo.refreshRecipes()
+			done <- true
+			results[i] = RefreshResult{Repo: pkgRepo.Name, Updated: updated, Err: err}
+			switch {
+			case err != nil:
+				ml.Printf(i, color.RedString("%v", err))
+			case updated:
+				ml.Printf(i, color.GreenString("Updated package recipes"))
+			default:
+				ml.Printf(i, color.HiBlackString("Package recipes are up to date"))
+			}
+		}()
+	}
+	wg.Wait()
+	var failed []string
+	for _, result := range results {
+		if result.Err != nil {
+			failed = append(failed, result.Repo)
+		}
+	}
+	if len(failed) != 0 {
+		return results, fmt.Errorf("unable to refresh package recipes for %s", strings.Join(failed, ", "))
+	}
+	return results, nil
+}
+
+// RefreshDueRepos refreshes the recipes of repos that are due per the refresh interval,
+// or of all repos with --refresh. Nothing is refreshed with --no-refresh or local recipes.
+// Failures are reported but not returned, since the current recipes can still be used.
+func (c *Config) RefreshDueRepos() error {
+	if utils.RecipeDirFlag != "" || utils.NoRefreshFlag {
+		return nil
+	}
+	var due []*PkgRepo
+	for _, pkgRepo := range c.PkgRepos {
+		shouldRefresh, err := pkgRepo.ShouldRefreshRecipes(c.RefreshInterval)
+		if err != nil {
+			return err
+		}
+		if shouldRefresh || utils.RefreshFlag {
+			due = append(due, pkgRepo)
+		}
+	}
+	// failures were already reported on each repo's line
+	RefreshRepos(due)
+	return nil
+}
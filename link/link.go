@@ -1,7 +1,9 @@
 package link
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -134,3 +136,53 @@ func CreateLinks(pkg string, ver string, confBinPaths []string, renames []pkgpar
 	}
 	return true, nil
 }
+
+// DanglingLinks returns the symlinks in a directory whose targets no longer exist
+func DanglingLinks(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var dangling []string
+	for _, entry := range entries {
+		if entry.Type()&os.ModeSymlink == 0 {
+			continue
+		}
+		linkPath := filepath.Join(dir, entry.Name())
+		if _, err := os.Stat(linkPath); os.IsNotExist(err) {
+			dangling = append(dangling, linkPath)
+		}
+	}
+	return dangling, nil
+}
+
+// RemoveBinLinks removes the links in the bin directory that point into a package,
+// which needs no recipe to find them
+func RemoveBinLinks(pkg string) error {
+	return removeLinksInto(utils.WebmanBinDir, pkg)
+}
+
+// removeLinksInto removes the symlinks under a directory that point into a package
+func removeLinksInto(dir string, pkg string) error {
+	pkgDir := filepath.Join(utils.WebmanPkgDir, pkg) + string(filepath.Separator)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type()&os.ModeSymlink == 0 {
+			return nil
+		}
+		target, err := os.Readlink(path)
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(target, pkgDir) {
+			return os.Remove(path)
+		}
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,89 @@
+package link
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/candrewlee14/webman/utils"
+)
+
+// LinkOwner is the installed package version a link in the bin directory points into
+type LinkOwner struct {
+	Link    string `json:"link"`
+	Target  string `json:"target"`
+	Pkg     string `json:"package"`
+	Version string `json:"version"`
+}
+
+// ResolveLink finds the package version that provides a binary in the bin directory
+func ResolveLink(bin string) (*LinkOwner, error) {
+	linkPath := filepath.Join(utils.WebmanBinDir, bin)
+	if utils.GOOS == "windows" && filepath.Ext(bin) == "" {
+		linkPath += ".exe"
+	}
+	target, err := os.Readlink(linkPath)
+	if err != nil {
+		return nil, err
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(utils.WebmanBinDir, target)
+	}
+	rel, err := filepath.Rel(utils.WebmanPkgDir, target)
+	if err != nil {
+		return nil, err
+	}
+	parts := strings.Split(rel, string(filepath.Separator))
+	if len(parts) < 3 || parts[0] == ".." {
+		return nil, errors.New(linkPath + " does not point into a webman package")
+	}
+	// versions like 2.0.0-rc1 contain dashes, so the package name is trimmed instead of splitting the stem
+	ver := strings.TrimPrefix(parts[1], parts[0]+"-")
+	return &LinkOwner{Link: linkPath, Target: target, Pkg: parts[0], Version: ver}, nil
+}
+
+// PathMatches returns every executable with the given name on the PATH, in PATH order
+func PathMatches(name string) []string {
+	exts := []string{""}
+	if utils.GOOS == "windows" && filepath.Ext(name) == "" {
+		exts = filepath.SplitList(strings.ToLower(os.Getenv("PATHEXT")))
+		if len(exts) == 0 {
+			exts = []string{".com", ".exe", ".bat", ".cmd"}
+		}
+	}
+	var matches []string
+	seen := make(map[string]bool)
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" || seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		for _, ext := range exts {
+			path := filepath.Join(dir, name+ext)
+			fi, err := os.Stat(path)
+			if err != nil || fi.IsDir() {
+				continue
+			}
+			if utils.GOOS != "windows" && fi.Mode()&0o111 == 0 {
+				continue
+			}
+			matches = append(matches, path)
+			break
+		}
+	}
+	return matches
+}
+
+// Shadows returns the executables with the given name that run instead of the one in the bin directory,
+// because they come before it on the PATH. When the bin directory isn't on the PATH, every match shadows it.
+func Shadows(name string) []string {
+	var shadows []string
+	for _, match := range PathMatches(name) {
+		if filepath.Dir(match) == utils.WebmanBinDir {
+			break
+		}
+		shadows = append(shadows, match)
+	}
+	return shadows
+}
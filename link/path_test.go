@@ -0,0 +1,52 @@
+package link
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/candrewlee14/webman/utils"
+
+	"github.com/matryer/is"
+)
+
+func TestResolveLinkAndShadows(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symlinks needs extra privileges on Windows")
+	}
+	assert := is.New(t)
+
+	tmp := t.TempDir()
+	utils.Init(tmp)
+	target := filepath.Join(utils.WebmanPkgDir, "rg", "rg-14.0.0", "bin", "rg")
+	assert.NoErr(os.MkdirAll(filepath.Dir(target), os.ModePerm))              // Should create package dir
+	assert.NoErr(os.WriteFile(target, []byte("#!/bin/sh\n"), 0o755))          // Should create binary
+	assert.NoErr(os.Symlink(target, filepath.Join(utils.WebmanBinDir, "rg"))) // Should link binary
+
+	owner, err := ResolveLink("rg")
+	assert.NoErr(err)                     // Should resolve link
+	assert.Equal(owner.Pkg, "rg")         // Link should belong to the package
+	assert.Equal(owner.Version, "14.0.0") // Link should belong to the version
+	_, err = ResolveLink("fd")
+	assert.True(os.IsNotExist(err)) // Missing links should not resolve
+
+	target = filepath.Join(utils.WebmanPkgDir, "bat", "bat-2.0.0-rc1", "bat")
+	assert.NoErr(os.MkdirAll(filepath.Dir(target), os.ModePerm))               // Should create package dir
+	assert.NoErr(os.WriteFile(target, []byte("#!/bin/sh\n"), 0o755))           // Should create binary
+	assert.NoErr(os.Symlink(target, filepath.Join(utils.WebmanBinDir, "bat"))) // Should link binary
+	owner, err = ResolveLink("bat")
+	assert.NoErr(err)                        // Should resolve link
+	assert.Equal(owner.Version, "2.0.0-rc1") // Version with a dash should be kept whole
+
+	other := filepath.Join(tmp, "other")
+	assert.NoErr(os.MkdirAll(other, os.ModePerm))                                        // Should create other dir
+	assert.NoErr(os.WriteFile(filepath.Join(other, "rg"), []byte("#!/bin/sh\n"), 0o755)) // Should create other binary
+
+	t.Setenv("PATH", utils.WebmanBinDir+string(os.PathListSeparator)+other)
+	assert.Equal(len(Shadows("rg")), 0) // Later binaries should not shadow the link
+	t.Setenv("PATH", other+string(os.PathListSeparator)+utils.WebmanBinDir)
+	assert.Equal(Shadows("rg"), []string{filepath.Join(other, "rg")}) // Earlier binaries should shadow the link
+	t.Setenv("PATH", other)
+	assert.Equal(Shadows("rg"), []string{filepath.Join(other, "rg")}) // Binaries shadow links missing from the PATH
+}
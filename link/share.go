@@ -0,0 +1,147 @@
+package link
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/candrewlee14/webman/pkgparse"
+	"github.com/candrewlee14/webman/utils"
+)
+
+// completionDirs are where the completions for each shell are linked, relative to the share directory
+var completionDirs = map[string]string{
+	"bash":       filepath.Join("bash-completion", "completions"),
+	"zsh":        filepath.Join("zsh", "site-functions"),
+	"fish":       filepath.Join("fish", "vendor_completions.d"),
+	"powershell": "powershell",
+}
+
+// completionShells are the shells completions are linked for, in order
+var completionShells = []string{"bash", "zsh", "fish", "powershell"}
+
+// manPageExp matches man page file names like rg.1 or bat.1.gz, capturing the section
+var manPageExp = regexp.MustCompile(`\.([1-9])[a-z]*(\.gz)?$`)
+
+// CompletionDir is where completions for a shell are linked, like ~/.webman/share/zsh/site-functions
+func CompletionDir(shell string) string {
+	return filepath.Join(utils.WebmanShareDir, completionDirs[shell])
+}
+
+// ManDir is where man pages are linked, to be added to MANPATH
+func ManDir() string {
+	return filepath.Join(utils.WebmanShareDir, "man")
+}
+
+// ShareLinks returns the files and link paths for the completions and man pages of an installed package version,
+// including completions generated by post-install hooks
+func ShareLinks(pkgConf *pkgparse.PkgConfig, pkgVerStem string) ([]string, []string, error) {
+	var files []string
+	var linkPaths []string
+	pkgDir := filepath.Join(utils.WebmanPkgDir, pkgConf.Title, pkgVerStem)
+	completions := pkgConf.GetMyCompletions()
+	for shell := range completions {
+		if _, ok := completionDirs[shell]; !ok {
+			return nil, nil, errors.New("unsupported completion shell " + shell)
+		}
+	}
+	for _, shell := range completionShells {
+		var shellFiles []string
+		if relPath, ok := completions[shell]; ok {
+			recipeFiles, err := listFiles(filepath.Join(pkgDir, filepath.FromSlash(relPath)))
+			if err != nil {
+				return nil, nil, err
+			}
+			shellFiles = append(shellFiles, recipeFiles...)
+		}
+		generated, err := listFiles(filepath.Join(pkgDir, pkgparse.GeneratedCompletionsDir, shell))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, err
+		}
+		for _, file := range append(shellFiles, generated...) {
+			files = append(files, file)
+			linkPaths = append(linkPaths, filepath.Join(CompletionDir(shell), filepath.Base(file)))
+		}
+	}
+	for _, relPath := range pkgConf.GetMyManPaths() {
+		manFiles, err := listFiles(filepath.Join(pkgDir, filepath.FromSlash(relPath)))
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, file := range manFiles {
+			match := manPageExp.FindStringSubmatch(filepath.Base(file))
+			if match == nil {
+				continue
+			}
+			files = append(files, file)
+			linkPaths = append(linkPaths, filepath.Join(ManDir(), "man"+match[1], filepath.Base(file)))
+		}
+	}
+	return files, linkPaths, nil
+}
+
+// listFiles returns the path itself if it is a file, or the files directly inside it if it is a directory
+func listFiles(path string) ([]string, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return []string{path}, nil
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			files = append(files, filepath.Join(path, entry.Name()))
+		}
+	}
+	return files, nil
+}
+
+// CreateShareLinks links the completions and man pages of a package version into the share directory,
+// replacing the links of other versions of the package. It returns the created link paths.
+func CreateShareLinks(pkgConf *pkgparse.PkgConfig, pkgVerStem string) ([]string, error) {
+	if err := RemoveShareLinks(pkgConf.Title); err != nil {
+		return nil, err
+	}
+	files, linkPaths, err := ShareLinks(pkgConf, pkgVerStem)
+	if err != nil {
+		return nil, err
+	}
+	for i, linkPath := range linkPaths {
+		if err := os.MkdirAll(filepath.Dir(linkPath), os.ModePerm); err != nil {
+			return nil, err
+		}
+		if _, err := AddLink(files[i], linkPath); err != nil {
+			return nil, err
+		}
+	}
+	return linkPaths, nil
+}
+
+// RemoveShareLinks removes the links in the share directory that point into a package's directory
+func RemoveShareLinks(pkg string) error {
+	return removeLinksInto(utils.WebmanShareDir, pkg)
+}
+
+// ShareHint explains how to make linked man pages and completions available,
+// unless the share directory is already set up by "webman shell-init"
+func ShareHint(linkPaths []string) string {
+	if len(linkPaths) == 0 {
+		return ""
+	}
+	for _, dir := range filepath.SplitList(os.Getenv("MANPATH")) {
+		if dir == ManDir() {
+			return ""
+		}
+	}
+	return "Linked completions and man pages into " + utils.WebmanShareDir +
+		`; set up your shell with "webman shell-init", or add ` + ManDir() + " to MANPATH and " +
+		CompletionDir("zsh") + " to fpath"
+}
@@ -0,0 +1,56 @@
+package link
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/candrewlee14/webman/pkgparse"
+	"github.com/candrewlee14/webman/utils"
+
+	"github.com/matryer/is"
+)
+
+func TestCreateShareLinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symlinks needs extra privileges on Windows")
+	}
+	assert := is.New(t)
+
+	utils.Init(t.TempDir())
+	pkgConf := &pkgparse.PkgConfig{
+		Title:       "rg",
+		Completions: map[string]string{"zsh": "complete/_rg"},
+		Man:         pkgparse.SingleOrMulti{Values: []string{"doc"}},
+	}
+	for _, stem := range []string{"rg-13.0.0", "rg-14.0.0"} {
+		pkgDir := filepath.Join(utils.WebmanPkgDir, "rg", stem)
+		for _, file := range []string{"complete/_rg", "doc/rg.1", "doc/README.md", ".webman-completions/fish/rg.fish"} {
+			path := filepath.Join(pkgDir, filepath.FromSlash(file))
+			assert.NoErr(os.MkdirAll(filepath.Dir(path), os.ModePerm)) // Should create package dir
+			assert.NoErr(os.WriteFile(path, nil, os.ModePerm))         // Should create package file
+		}
+	}
+
+	linkPaths, err := CreateShareLinks(pkgConf, "rg-13.0.0")
+	assert.NoErr(err) // Should link completions and man pages
+	assert.Equal(linkPaths, []string{
+		filepath.Join(CompletionDir("zsh"), "_rg"),
+		filepath.Join(CompletionDir("fish"), "rg.fish"),
+		filepath.Join(ManDir(), "man1", "rg.1"),
+	}) // Recipe, generated and man page files should be linked, skipping non-man pages
+
+	_, err = CreateShareLinks(pkgConf, "rg-14.0.0")
+	assert.NoErr(err) // Should switch links to another version
+	target, err := os.Readlink(filepath.Join(ManDir(), "man1", "rg.1"))
+	assert.NoErr(err)                                                                         // Man page should be linked
+	assert.Equal(target, filepath.Join(utils.WebmanPkgDir, "rg", "rg-14.0.0", "doc", "rg.1")) // Link should point at the new version
+
+	assert.NoErr(os.Symlink(filepath.Join(utils.WebmanPkgDir, "fd", "fd-8.0.0", "fd.1"), filepath.Join(ManDir(), "man1", "fd.1"))) // Should link another package
+	assert.NoErr(RemoveShareLinks("rg"))                                                                                           // Should remove links
+	_, err = os.Lstat(filepath.Join(CompletionDir("zsh"), "_rg"))
+	assert.True(os.IsNotExist(err)) // Package links should be removed
+	_, err = os.Lstat(filepath.Join(ManDir(), "man1", "fd.1"))
+	assert.NoErr(err) // Links of other packages should be kept
+}
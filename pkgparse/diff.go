@@ -0,0 +1,200 @@
+package pkgparse
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/candrewlee14/webman/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// descriptiveFields are recipe fields that only describe a package,
+// changes to any other field can change what is downloaded
+var descriptiveFields = map[string]struct{}{
+	"tagline":      {},
+	"about":        {},
+	"install_note": {},
+	"remove_note":  {},
+	"title":        {},
+	"description":  {},
+}
+
+// FieldChange is a changed field of a recipe, given as a dotted path such as os_map.linux.ext
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+}
+
+// AffectsDownload determines whether the change can change what is downloaded,
+// like URLs, versions, file names and platforms
+func (change FieldChange) AffectsDownload() bool {
+	parts := strings.Split(change.Field, ".")
+	_, ok := descriptiveFields[parts[len(parts)-1]]
+	return !ok
+}
+
+// RecipeDiff is a recipe that exists in both trees but was modified
+type RecipeDiff struct {
+	Name    string        `json:"name"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// RepoDiff is the difference between two recipe trees of a package repository
+type RepoDiff struct {
+	AddedPkgs      []string     `json:"added_pkgs"`
+	RemovedPkgs    []string     `json:"removed_pkgs"`
+	ModifiedPkgs   []RecipeDiff `json:"modified_pkgs"`
+	AddedGroups    []string     `json:"added_groups"`
+	RemovedGroups  []string     `json:"removed_groups"`
+	ModifiedGroups []RecipeDiff `json:"modified_groups"`
+}
+
+// IsEmpty determines whether the trees have the same recipes
+func (diff *RepoDiff) IsEmpty() bool {
+	return len(diff.AddedPkgs) == 0 && len(diff.RemovedPkgs) == 0 && len(diff.ModifiedPkgs) == 0 &&
+		len(diff.AddedGroups) == 0 && len(diff.RemovedGroups) == 0 && len(diff.ModifiedGroups) == 0
+}
+
+// DiffRecipes compares the package and group recipes of two package repository trees.
+// A missing old tree is treated as empty.
+func DiffRecipes(oldPath string, newPath string) (*RepoDiff, error) {
+	var diff RepoDiff
+	var err error
+	diff.AddedPkgs, diff.RemovedPkgs, diff.ModifiedPkgs, err = diffRecipeDir(
+		filepath.Join(oldPath, "pkgs"), filepath.Join(newPath, "pkgs"), utils.PkgRecipeExt)
+	if err != nil {
+		return nil, err
+	}
+	diff.AddedGroups, diff.RemovedGroups, diff.ModifiedGroups, err = diffRecipeDir(
+		filepath.Join(oldPath, "groups"), filepath.Join(newPath, "groups"), utils.GroupRecipeExt)
+	if err != nil {
+		return nil, err
+	}
+	return &diff, nil
+}
+
+func diffRecipeDir(oldDir string, newDir string, ext string) ([]string, []string, []RecipeDiff, error) {
+	oldRecipes, err := readRecipeDir(oldDir, ext)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	newRecipes, err := readRecipeDir(newDir, ext)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	added := []string{}
+	removed := []string{}
+	modified := []RecipeDiff{}
+	for name, newData := range newRecipes {
+		oldData, ok := oldRecipes[name]
+		if !ok {
+			added = append(added, name)
+			continue
+		}
+		if bytes.Equal(oldData, newData) {
+			continue
+		}
+		changes, err := diffRecipe(oldData, newData)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("%s: %v", name, err)
+		}
+		if len(changes) != 0 {
+			modified = append(modified, RecipeDiff{Name: name, Changes: changes})
+		}
+	}
+	for name := range oldRecipes {
+		if _, ok := newRecipes[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	sort.Slice(modified, func(i, j int) bool {
+		return modified[i].Name < modified[j].Name
+	})
+	return added, removed, modified, nil
+}
+
+// readRecipeDir reads all recipes in a directory, keyed by name
+func readRecipeDir(dir string, ext string) (map[string][]byte, error) {
+	recipes := make(map[string][]byte)
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return recipes, nil
+		}
+		return nil, err
+	}
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), ext) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		recipes[strings.TrimSuffix(file.Name(), ext)] = data
+	}
+	return recipes, nil
+}
+
+func diffRecipe(oldData []byte, newData []byte) ([]FieldChange, error) {
+	oldFields := make(map[string]string)
+	newFields := make(map[string]string)
+	for _, recipe := range []struct {
+		data   []byte
+		fields map[string]string
+	}{{oldData, oldFields}, {newData, newFields}} {
+		var value any
+		if err := yaml.Unmarshal(recipe.data, &value); err != nil {
+			return nil, err
+		}
+		flattenRecipe("", value, recipe.fields)
+	}
+	var changes []FieldChange
+	for field, newValue := range newFields {
+		if oldValue := oldFields[field]; oldValue != newValue {
+			changes = append(changes, FieldChange{Field: field, Old: oldValue, New: newValue})
+		}
+	}
+	for field, oldValue := range oldFields {
+		if _, ok := newFields[field]; !ok {
+			changes = append(changes, FieldChange{Field: field, Old: oldValue})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes, nil
+}
+
+// flattenRecipe flattens a decoded YAML value into dotted field paths
+func flattenRecipe(prefix string, value any, fields map[string]string) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		for key, inner := range v {
+			flattenRecipe(join(key), inner, fields)
+		}
+	case []any:
+		for i, inner := range v {
+			flattenRecipe(join(fmt.Sprint(i)), inner, fields)
+		}
+	case nil:
+	default:
+		fields[prefix] = fmt.Sprint(v)
+	}
+}
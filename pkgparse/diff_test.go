@@ -0,0 +1,48 @@
+package pkgparse
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/candrewlee14/webman/utils"
+
+	"github.com/matryer/is"
+)
+
+func TestDiffRecipes(t *testing.T) {
+	assert := is.New(t)
+
+	oldPath := t.TempDir()
+	newPath := t.TempDir()
+	writeRecipe := func(root string, pkg string, data string) {
+		assert.NoErr(os.MkdirAll(filepath.Join(root, "pkgs"), os.ModePerm))                                        // Should create pkgs dir
+		assert.NoErr(os.WriteFile(filepath.Join(root, "pkgs", pkg+utils.PkgRecipeExt), []byte(data), os.ModePerm)) // Should write recipe
+	}
+	writeRecipe(oldPath, "same", "tagline: same\n")
+	writeRecipe(newPath, "same", "tagline: same\n")
+	writeRecipe(oldPath, "gone", "tagline: gone\n")
+	writeRecipe(newPath, "new", "tagline: new\n")
+	writeRecipe(oldPath, "changed", "tagline: old\nbase_download_url: https://a.example\nos_map:\n  linux:\n    ext: tar.gz\n")
+	writeRecipe(newPath, "changed", "tagline: new\nbase_download_url: https://b.example\nos_map:\n  linux:\n    ext: zip\n")
+
+	diff, err := DiffRecipes(oldPath, newPath)
+	assert.NoErr(err)                                  // Should diff recipe trees
+	assert.Equal(diff.AddedPkgs, []string{"new"})      // Should find added recipe
+	assert.Equal(diff.RemovedPkgs, []string{"gone"})   // Should find removed recipe
+	assert.Equal(len(diff.ModifiedPkgs), 1)            // Should find a single modified recipe
+	assert.Equal(diff.ModifiedPkgs[0].Name, "changed") // Should find modified recipe
+	changes := diff.ModifiedPkgs[0].Changes
+	assert.Equal(len(changes), 3)                       // Should find each changed field
+	assert.Equal(changes[0].Field, "base_download_url") // Should sort changes by field
+	assert.True(changes[0].AffectsDownload())           // URL changes should affect downloads
+	assert.Equal(changes[1].Field, "os_map.linux.ext")  // Should flatten nested fields
+	assert.Equal(changes[1].Old, "tar.gz")              // Should keep the old value
+	assert.Equal(changes[1].New, "zip")                 // Should keep the new value
+	assert.True(!changes[2].AffectsDownload())          // Tagline changes should not affect downloads
+	assert.True(!diff.IsEmpty())                        // Diff should not be empty
+
+	diff, err = DiffRecipes(filepath.Join(oldPath, "missing"), oldPath)
+	assert.NoErr(err)                    // Should diff against a missing tree
+	assert.Equal(len(diff.AddedPkgs), 3) // Should add every recipe
+}
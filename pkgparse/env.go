@@ -0,0 +1,67 @@
+package pkgparse
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/candrewlee14/webman/utils"
+)
+
+// EnvVar is an environment variable set by a package version in use
+type EnvVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	// Pkg is the package that sets the variable
+	Pkg string `json:"package"`
+}
+
+// EnvVars returns the environment variables a package version sets while it is in use,
+// with OS-level values overriding package-level ones
+func (pkgConf *PkgConfig) EnvVars(pkgVerStem string) map[string]string {
+	osEnv := pkgConf.MyOsInfo().Env
+	if len(pkgConf.Env) == 0 && len(osEnv) == 0 {
+		return nil
+	}
+	pkgDir := filepath.Join(utils.WebmanPkgDir, pkgConf.Title, pkgVerStem)
+	_, ver := utils.ParseStem(pkgVerStem)
+	vars := pkgConf.pkgVars(pkgDir, ver)
+	env := make(map[string]string)
+	for _, values := range []map[string]string{pkgConf.Env, osEnv} {
+		for name, value := range values {
+			env[name] = vars.expand(value)
+		}
+	}
+	return env
+}
+
+// ActiveEnv returns the environment variables of all package versions in use, sorted by name.
+// When packages set the same variable, the first package alphabetically wins.
+func ActiveEnv() ([]EnvVar, error) {
+	vars := []EnvVar{}
+	seen := make(map[string]bool)
+	for _, pkg := range utils.InstalledPackages() {
+		usingInfo, err := readUsing(pkg)
+		if err != nil {
+			return nil, err
+		}
+		if usingInfo == nil {
+			continue
+		}
+		names := make([]string, 0, len(usingInfo.Env))
+		for name := range usingInfo.Env {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			vars = append(vars, EnvVar{Name: name, Value: usingInfo.Env[name], Pkg: pkg})
+		}
+	}
+	sort.Slice(vars, func(i, j int) bool {
+		return vars[i].Name < vars[j].Name
+	})
+	return vars, nil
+}
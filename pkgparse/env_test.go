@@ -0,0 +1,42 @@
+package pkgparse
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/candrewlee14/webman/utils"
+
+	"github.com/matryer/is"
+)
+
+func TestActiveEnv(t *testing.T) {
+	assert := is.New(t)
+
+	tmp := t.TempDir()
+	utils.Init(tmp)
+	for _, pkg := range []string{"go", "java"} {
+		assert.NoErr(os.MkdirAll(filepath.Join(utils.WebmanPkgDir, pkg), os.ModePerm)) // Should create package dir
+	}
+
+	goConf := &PkgConfig{
+		Title: "go",
+		Env:   map[string]string{"GOROOT": "[PKG_DIR]", "GOVER": "[VER]"},
+		OsMap: map[string]OsInfo{GOOStoPkgOs[utils.GOOS]: {Env: map[string]string{"GOVER": "go[VER]"}}},
+	}
+	assert.NoErr(WriteUsing("go", "go-1.22.1"))     // Should write using file
+	assert.NoErr(goConf.WriteUsingEnv("go-1.22.1")) // Should record env of version in use
+	assert.NoErr(WriteUsing("java", "java-21"))     // Should write using file without env
+
+	vars, err := ActiveEnv()
+	assert.NoErr(err) // Should read active env
+	assert.Equal(vars, []EnvVar{
+		{Name: "GOROOT", Value: filepath.Join(utils.WebmanPkgDir, "go", "go-1.22.1"), Pkg: "go"},
+		{Name: "GOVER", Value: "go1.22.1", Pkg: "go"},
+	}) // OS values should override package values
+
+	assert.NoErr(WriteUsing("go", "go-1.21.0")) // Switching should reset env
+	vars, err = ActiveEnv()
+	assert.NoErr(err)          // Should read active env
+	assert.Equal(len(vars), 0) // Env of the previous version should be gone
+}
@@ -1,37 +1,22 @@
 package pkgparse
 
 import (
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 )
 
+// getGiteaReleaseTags lists the releases of a repo, newest first, paging like getGithubReleaseTags
+func getGiteaReleaseTags(baseURL string, user string, repo string, done func([]ReleaseTagInfo) bool) ([]ReleaseTagInfo, error) {
+	url := fmt.Sprintf("%s/api/v1/repos/%s/%s/releases?limit=50", baseURL, user, repo)
+	return getReleaseTagPages(url, "gitea", done)
+}
+
 func getLatestGiteaReleaseTag(baseURL string, user string, repo string, allowPrerelease bool) (*ReleaseTagInfo, error) {
-	url := fmt.Sprintf("%s/api/v1/repos/%s/%s/releases", baseURL, user, repo)
-	r, err := http.Get(url)
-	if err != nil {
-		return nil, err
-	}
-	defer r.Body.Close()
-	if !(r.StatusCode >= 200 && r.StatusCode < 300) {
-		return nil, fmt.Errorf("bad HTTP Response: %s", r.Status)
-	}
-	body, err := ioutil.ReadAll(r.Body)
+	releases, err := getGiteaReleaseTags(baseURL, user, repo, stableReleaseListed(allowPrerelease))
 	if err != nil {
 		return nil, err
 	}
-	var releases []ReleaseTagInfo
-	if err = json.Unmarshal(body, &releases); err != nil {
-		return nil, fmt.Errorf("gitea releases JSON response not in expected format")
-	}
-	if len(releases) == 0 {
-		return nil, fmt.Errorf("expected at least one release listed at %s, unable to resolve latest", url)
-	}
-	for _, release := range releases {
-		if (allowPrerelease || !release.Prerelease) && !release.Draft {
-			return &release, nil
-		}
+	if release := firstStableRelease(releases, allowPrerelease); release != nil {
+		return release, nil
 	}
 	return nil, fmt.Errorf("found no stable releases for %s/%s", user, repo)
 }
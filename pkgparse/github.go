@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
 )
 
 type ReleaseInfo struct {
@@ -27,31 +28,87 @@ type ReleaseTagInfo struct {
 	Draft      bool
 }
 
-func getLatestGithubReleaseTag(user string, repo string, allowPrerelease bool) (*ReleaseTagInfo, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases", user, repo)
-	r, err := http.Get(url)
-	if err != nil {
-		return nil, err
+// getGithubReleaseTags lists the releases of a repo, newest first.
+// It follows the pages of the listing until done returns true for the releases so far, or all pages are fetched if done is nil.
+func getGithubReleaseTags(user string, repo string, done func([]ReleaseTagInfo) bool) ([]ReleaseTagInfo, error) {
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases?per_page=100", user, repo)
+	return getReleaseTagPages(url, "github", done)
+}
+
+// getReleaseTagPages fetches the pages of a release listing, following the "next" links of the Link header
+func getReleaseTagPages(url string, source string, done func([]ReleaseTagInfo) bool) ([]ReleaseTagInfo, error) {
+	firstUrl := url
+	var releases []ReleaseTagInfo
+	for url != "" {
+		r, err := http.Get(url)
+		if err != nil {
+			return nil, err
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		r.Body.Close()
+		if !(r.StatusCode >= 200 && r.StatusCode < 300) {
+			return nil, fmt.Errorf("bad HTTP Response: %s", r.Status)
+		}
+		if err != nil {
+			return nil, err
+		}
+		var page []ReleaseTagInfo
+		if err = json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("%s releases JSON response not in expected format", source)
+		}
+		releases = append(releases, page...)
+		if len(page) == 0 || (done != nil && done(releases)) {
+			break
+		}
+		url = nextPageUrl(r.Header.Get("Link"))
 	}
-	defer r.Body.Close()
-	if !(r.StatusCode >= 200 && r.StatusCode < 300) {
-		return nil, fmt.Errorf("bad HTTP Response: %s", r.Status)
+	if len(releases) == 0 {
+		return nil, fmt.Errorf("expected at least one release listed at %s, unable to resolve latest", firstUrl)
+	}
+	return releases, nil
+}
+
+// nextPageUrl returns the URL of the next page from a Link header like
+// <https://api.github.com/...&page=2>; rel="next", <...>; rel="last", or "" on the last page
+func nextPageUrl(linkHeader string) string {
+	for _, link := range strings.Split(linkHeader, ",") {
+		target, params, ok := strings.Cut(link, ";")
+		if !ok {
+			continue
+		}
+		for _, param := range strings.Split(params, ";") {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return strings.Trim(strings.TrimSpace(target), "<>")
+			}
+		}
 	}
-	body, err := ioutil.ReadAll(r.Body)
+	return ""
+}
+
+func getLatestGithubReleaseTag(user string, repo string, allowPrerelease bool) (*ReleaseTagInfo, error) {
+	releases, err := getGithubReleaseTags(user, repo, stableReleaseListed(allowPrerelease))
 	if err != nil {
 		return nil, err
 	}
-	var releases []ReleaseTagInfo
-	if err = json.Unmarshal(body, &releases); err != nil {
-		return nil, fmt.Errorf("github releases JSON response not in expected format")
-	}
-	if len(releases) == 0 {
-		return nil, fmt.Errorf("expected at least one release listed at %s, unable to resolve latest", url)
+	if release := firstStableRelease(releases, allowPrerelease); release != nil {
+		return release, nil
 	}
-	for _, release := range releases {
+	return nil, fmt.Errorf("found no stable releases for %s/%s", user, repo)
+}
+
+// firstStableRelease returns the newest release that isn't a draft or, unless allowed, a prerelease
+func firstStableRelease(releases []ReleaseTagInfo, allowPrerelease bool) *ReleaseTagInfo {
+	for i, release := range releases {
 		if (allowPrerelease || !release.Prerelease) && !release.Draft {
-			return &release, nil
+			return &releases[i]
 		}
 	}
-	return nil, fmt.Errorf("found no stable releases for %s/%s", user, repo)
+	return nil
+}
+
+// stableReleaseListed stops fetching releases once a stable release is found
+func stableReleaseListed(allowPrerelease bool) func([]ReleaseTagInfo) bool {
+	return func(releases []ReleaseTagInfo) bool {
+		return firstStableRelease(releases, allowPrerelease) != nil
+	}
 }
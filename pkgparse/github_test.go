@@ -0,0 +1,120 @@
+package pkgparse
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/candrewlee14/webman/utils"
+
+	"github.com/matryer/is"
+)
+
+func TestNextPageUrl(t *testing.T) {
+	assert := is.New(t)
+
+	link := `<https://api.github.com/repositories/1/releases?page=2>; rel="next", <https://api.github.com/repositories/1/releases?page=5>; rel="last"`
+	assert.Equal(nextPageUrl(link), "https://api.github.com/repositories/1/releases?page=2") // Should find the next page
+	link = `<https://api.github.com/repositories/1/releases?page=1>; rel="first", <https://api.github.com/repositories/1/releases?page=4>; rel="prev"`
+	assert.Equal(nextPageUrl(link), "") // Last page should have no next page
+	assert.Equal(nextPageUrl(""), "")   // Unpaged response should have no next page
+}
+
+func TestReleasePages(t *testing.T) {
+	assert := is.New(t)
+
+	requests := 0
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/api/v1/repos/example/foo/releases?limit=50&page=2>; rel="next"`, srv.URL))
+			fmt.Fprint(w, `[{"tag_name":"v2.0.0-rc1","prerelease":true}]`)
+		case "2":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/api/v1/repos/example/foo/releases?limit=50&page=3>; rel="next"`, srv.URL))
+			fmt.Fprint(w, `[{"tag_name":"v1.1.0"},{"tag_name":"v1.0.0"}]`)
+		case "3":
+			fmt.Fprint(w, `[{"tag_name":"v0.9.0"}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	pkgConf := &PkgConfig{
+		Title:          "foo",
+		LatestStrategy: "gitea-release",
+		GiteaURL:       srv.URL,
+		GitUser:        "example",
+		GitRepo:        "foo",
+		VersionFormat:  "^v[VER]$",
+	}
+
+	versions, err := pkgConf.GetVersions()
+	assert.NoErr(err)                                           // Should list versions
+	assert.Equal(versions, []string{"1.1.0", "1.0.0", "0.9.0"}) // Should follow every page
+	assert.Equal(requests, 3)                                   // Should fetch each page once
+
+	requests = 0
+	latest, err := pkgConf.GetLatestVersion()
+	assert.NoErr(err)              // Should find latest version
+	assert.Equal(*latest, "1.1.0") // Should skip prereleases on earlier pages
+	assert.Equal(requests, 2)      // Should stop fetching once a stable release is listed
+}
+
+func TestGetMatchingVersion(t *testing.T) {
+	assert := is.New(t)
+
+	requests := 0
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/api/v1/repos/example/foo/releases?limit=50&page=2>; rel="next"`, srv.URL))
+			fmt.Fprint(w, `[{"tag_name":"v2.0.0"}]`)
+		case "2":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/api/v1/repos/example/foo/releases?limit=50&page=3>; rel="next"`, srv.URL))
+			fmt.Fprint(w, `[{"tag_name":"v1.21.10"},{"tag_name":"v1.22.0"},{"tag_name":"v1.21.9"}]`)
+		case "3":
+			fmt.Fprint(w, `[{"tag_name":"v1.0.0"}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	newPkgConf := func() *PkgConfig {
+		return &PkgConfig{
+			Title:          "foo",
+			LatestStrategy: "gitea-release",
+			GiteaURL:       srv.URL,
+			GitUser:        "example",
+			GitRepo:        "foo",
+			VersionFormat:  "^v[VER]$",
+		}
+	}
+
+	ver, err := newPkgConf().GetMatchingVersion("1.x")
+	assert.NoErr(err)            // Should find matching version
+	assert.Equal(*ver, "1.22.0") // Should pick the highest match, not the newest listed
+	assert.Equal(requests, 2)    // Should stop fetching once a match is listed
+
+	requests = 0
+	_, err = newPkgConf().GetMatchingVersion("3.x")
+	assert.True(err != nil)   // Should not match an unreleased version
+	assert.Equal(requests, 3) // Should fetch every page without a match
+
+	requests = 0
+	tag, err := newPkgConf().ReleaseTag("1.21.9")
+	assert.NoErr(err)            // Should find release tag
+	assert.Equal(tag, "v1.21.9") // Should return the tag the version was parsed from
+	assert.Equal(requests, 2)    // Should stop fetching once the version is listed
+
+	requests = 0
+	utils.Init(t.TempDir())
+	versions, err := newPkgConf().GetCachedVersions()
+	assert.NoErr(err)                         // Should list recent versions
+	assert.Equal(versions, []string{"2.0.0"}) // Should only list the first page
+	assert.Equal(requests, 1)                 // Should stop fetching after the first page
+}
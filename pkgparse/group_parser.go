@@ -7,6 +7,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/candrewlee14/webman/config"
 	"github.com/candrewlee14/webman/utils"
@@ -16,10 +17,90 @@ import (
 )
 
 type PkgGroupConfig struct {
-	Title       string   `yaml:"title"`
-	Tagline     string   `yaml:"tagline"`
-	Description string   `yaml:"description"`
-	Packages    []string `yaml:"packages"`
+	Title       string         `yaml:"title,omitempty"`
+	Tagline     string         `yaml:"tagline,omitempty"`
+	Description string         `yaml:"description,omitempty"`
+	Packages    []GroupPackage `yaml:"packages,omitempty"`
+	// Groups are other groups whose packages are included in this group
+	Groups []string `yaml:"groups,omitempty"`
+}
+
+// GroupPackage is a package in a group, given either as 'pkg', 'pkg@version' or 'repo/pkg@version',
+// or as a mapping with the options below
+type GroupPackage struct {
+	Name string `yaml:"name"`
+	Repo string `yaml:"repo,omitempty"`
+	// Version is an exact version or a constraint like 1.22.x, the latest version if empty
+	Version string `yaml:"version,omitempty"`
+	// Optional packages are only installed when selected, not with --all
+	Optional bool `yaml:"optional,omitempty"`
+	// Default packages are selected initially when prompting for packages
+	Default bool `yaml:"default,omitempty"`
+	// Os restricts the package to the given operating systems
+	Os []string `yaml:"os,omitempty"`
+}
+
+func (p *GroupPackage) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var arg string
+	if err := unmarshal(&arg); err == nil {
+		repo, pkg, ver, err := utils.ParsePkgVer(arg)
+		if err != nil {
+			return err
+		}
+		*p = GroupPackage{Name: pkg, Repo: repo, Version: ver}
+		return nil
+	}
+	type plain GroupPackage
+	if err := unmarshal((*plain)(p)); err != nil {
+		return err
+	}
+	if p.Name == "" {
+		return errors.New("group package entries need a name")
+	}
+	return nil
+}
+
+// MarshalYAML writes packages without options in the short 'pkg@version' form
+func (p GroupPackage) MarshalYAML() (interface{}, error) {
+	if !p.Optional && !p.Default && len(p.Os) == 0 {
+		return p.Arg(), nil
+	}
+	type plain GroupPackage
+	return plain(p), nil
+}
+
+// Arg is the package argument to install this package, like 'go' or 'go@1.22.x'
+func (p GroupPackage) Arg() string {
+	arg := p.Name
+	if p.Repo != "" {
+		arg = p.Repo + "/" + arg
+	}
+	if p.Version != "" {
+		arg += "@" + p.Version
+	}
+	return arg
+}
+
+// SupportsOs determines whether the package is meant for the given OS
+func (p GroupPackage) SupportsOs(pkgOs string) bool {
+	if len(p.Os) == 0 {
+		return true
+	}
+	for _, supportedOs := range p.Os {
+		if supportedOs == pkgOs {
+			return true
+		}
+	}
+	return false
+}
+
+// PackageArgs returns the package arguments of the packages listed directly in the group
+func (groupConf *PkgGroupConfig) PackageArgs() []string {
+	args := make([]string, 0, len(groupConf.Packages))
+	for _, pkg := range groupConf.Packages {
+		args = append(args, pkg.Arg())
+	}
+	return args
 }
 
 func ParseGroupConfig(r io.Reader, name string) (*PkgGroupConfig, error) {
@@ -31,7 +112,7 @@ func ParseGroupConfig(r io.Reader, name string) (*PkgGroupConfig, error) {
 	if err = yaml.Unmarshal(data, &groupConf); err != nil {
 		return nil, fmt.Errorf("invalid format for package group: %v", err)
 	}
-	if len(groupConf.Packages) == 0 {
+	if len(groupConf.Packages) == 0 && len(groupConf.Groups) == 0 {
 		return nil, fmt.Errorf("no packages in package group %s", color.YellowString(name))
 	}
 	return &groupConf, nil
@@ -49,24 +130,33 @@ func ParseGroupConfigInRepo(pkgRepo *config.PkgRepo, group string) (*PkgGroupCon
 	return groupCfg, err
 }
 
-func ParseGroupConfigLocal(pkgRepos []*config.PkgRepo, group string) (*PkgGroupConfig, string, error) {
-	var groupConfPath string
-	var repo string
-	for _, pkgRepo := range pkgRepos {
-		groupPath := filepath.Join(pkgRepo.Path(), "groups", group+utils.GroupRecipeExt)
+// FindGroupPath returns the path of a group file and the name of the repo providing it.
+// Local groups in ~/.webman/groups take precedence over groups from package repositories.
+func FindGroupPath(pkgRepos []*config.PkgRepo, group string) (string, string, error) {
+	localPath := LocalGroupPath(group)
+	if _, err := os.Stat(localPath); err == nil {
+		return localPath, LocalGroupRepo, nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return "", "", err
+	}
+	for _, pkgRepo := range config.ByPriority(pkgRepos) {
+		groupPath := filepath.Join(pkgRepo.GroupPath(), group+utils.GroupRecipeExt)
 		_, err := os.Stat(groupPath)
 		if err != nil {
 			if !errors.Is(err, fs.ErrNotExist) {
-				return nil, "", err
+				return "", "", err
 			}
 			continue
 		}
-		groupConfPath = groupPath
-		repo = pkgRepo.Path()
-		break
+		return groupPath, pkgRepo.Name, nil
 	}
-	if groupConfPath == "" {
-		return nil, "", fmt.Errorf("no package group exists for %s", group)
+	return "", "", fmt.Errorf("no package group exists for %s", group)
+}
+
+func ParseGroupConfigLocal(pkgRepos []*config.PkgRepo, group string) (*PkgGroupConfig, string, error) {
+	groupConfPath, repo, err := FindGroupPath(pkgRepos, group)
+	if err != nil {
+		return nil, "", err
 	}
 
 	fi, err := os.Open(groupConfPath)
@@ -79,14 +169,51 @@ func ParseGroupConfigLocal(pkgRepos []*config.PkgRepo, group string) (*PkgGroupC
 	return groupCfg, repo, err
 }
 
-func ParseGroupPackages(repoPath string, pkgs []string) ([]*PkgConfig, error) {
-	pkgConfigs := make([]*PkgConfig, 0, len(pkgs))
+// ParseGroupPackages returns the packages of a group for this OS, including the packages of included groups.
+// Entries in a group override entries for the same package from the groups it includes.
+func ParseGroupPackages(pkgRepos []*config.PkgRepo, group string) ([]GroupPackage, error) {
+	var pkgs []GroupPackage
+	if err := resolveGroup(pkgRepos, group, nil, &pkgs); err != nil {
+		return nil, err
+	}
+	pkgOs := GOOStoPkgOs[utils.GOOS]
+	supported := make([]GroupPackage, 0, len(pkgs))
 	for _, pkg := range pkgs {
-		pkgConfig, err := ParsePkgConfigPath(repoPath, pkg)
-		if err != nil {
-			return nil, err
+		if pkg.SupportsOs(pkgOs) {
+			supported = append(supported, pkg)
+		}
+	}
+	return supported, nil
+}
+
+func resolveGroup(pkgRepos []*config.PkgRepo, group string, stack []string, pkgs *[]GroupPackage) error {
+	for i, parent := range stack {
+		if parent == group {
+			return fmt.Errorf("package group cycle: %s", strings.Join(append(stack[i:], group), " -> "))
+		}
+	}
+	stack = append(stack, group)
+	groupConf, _, err := ParseGroupConfigLocal(pkgRepos, group)
+	if err != nil {
+		return err
+	}
+	for _, included := range groupConf.Groups {
+		if err := resolveGroup(pkgRepos, included, stack, pkgs); err != nil {
+			return err
+		}
+	}
+	for _, pkg := range groupConf.Packages {
+		overridden := false
+		for i := range *pkgs {
+			if (*pkgs)[i].Name == pkg.Name {
+				(*pkgs)[i] = pkg
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			*pkgs = append(*pkgs, pkg)
 		}
-		pkgConfigs = append(pkgConfigs, pkgConfig)
 	}
-	return pkgConfigs, nil
+	return nil
 }
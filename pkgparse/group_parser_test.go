@@ -0,0 +1,87 @@
+package pkgparse
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/candrewlee14/webman/config"
+	"github.com/candrewlee14/webman/utils"
+
+	"github.com/matryer/is"
+)
+
+func TestParseGroupPackages(t *testing.T) {
+	assert := is.New(t)
+
+	tmp := t.TempDir()
+	utils.Init(tmp)
+	pkgRepo := &config.PkgRepo{Name: "webman"}
+	assert.NoErr(os.MkdirAll(pkgRepo.GroupPath(), os.ModePerm)) // Should create groups dir
+	writeGroup := func(group string, data string) {
+		err := os.WriteFile(filepath.Join(pkgRepo.GroupPath(), group+utils.GroupRecipeExt), []byte(data), 0o644)
+		assert.NoErr(err) // Should write group
+	}
+	writeGroup("core-cli", `packages:
+  - rg
+  - jq
+  - name: bat
+    os: [plan9]
+`)
+	writeGroup("backend-dev", `groups:
+  - core-cli
+packages:
+  - go@1.22.x
+  - name: jq
+    version: "1.7"
+    default: true
+  - name: k9s
+    optional: true
+`)
+	pkgs, err := ParseGroupPackages([]*config.PkgRepo{pkgRepo}, "backend-dev")
+	assert.NoErr(err) // Should resolve nested group
+	var args []string
+	for _, pkg := range pkgs {
+		args = append(args, pkg.Arg())
+	}
+	assert.Equal(args, []string{"rg", "jq@1.7", "go@1.22.x", "k9s"}) // Should include, override and filter by OS
+	assert.True(pkgs[1].Default)                                     // Override should keep its options
+	assert.True(pkgs[3].Optional)                                    // Optional flag should be parsed
+
+	writeGroup("core-cli", `groups:
+  - backend-dev
+`)
+	_, err = ParseGroupPackages([]*config.PkgRepo{pkgRepo}, "backend-dev")
+	assert.True(err != nil) // Should detect group cycles
+}
+
+func TestLocalGroups(t *testing.T) {
+	assert := is.New(t)
+
+	tmp := t.TempDir()
+	utils.Init(tmp)
+	pkgRepo := &config.PkgRepo{Name: "webman"}
+	assert.NoErr(os.MkdirAll(pkgRepo.GroupPath(), os.ModePerm))   // Should create groups dir
+	assert.NoErr(os.MkdirAll(pkgRepo.PackagePath(), os.ModePerm)) // Should create pkgs dir
+	err := os.WriteFile(filepath.Join(pkgRepo.GroupPath(), "tools"+utils.GroupRecipeExt), []byte("packages:\n  - rg\n"), 0o644)
+	assert.NoErr(err) // Should write repo group
+
+	err = WriteLocalGroup("tools", &PkgGroupConfig{
+		Tagline:  "my tools",
+		Packages: []GroupPackage{{Name: "jq", Version: "1.7.x"}, {Name: "k9s", Optional: true}},
+	})
+	assert.NoErr(err)                                                                       // Should write local group
+	assert.True(WriteLocalGroup("../tools", &PkgGroupConfig{Groups: []string{"x"}}) != nil) // Should reject path-like names
+
+	groupConf, repo, err := ParseGroupConfigLocal([]*config.PkgRepo{pkgRepo}, "tools")
+	assert.NoErr(err)                                                  // Should parse group
+	assert.Equal(repo, LocalGroupRepo)                                 // Local group should take precedence
+	assert.Equal(groupConf.PackageArgs(), []string{"jq@1.7.x", "k9s"}) // Should round-trip packages
+	assert.True(groupConf.Packages[1].Optional)                        // Should round-trip options
+
+	idx, err := LoadIndex([]*config.PkgRepo{pkgRepo})
+	assert.NoErr(err) // Should load index
+	groups := idx.Groups()
+	assert.Equal(len(groups), 2)                 // Should list local and repo groups
+	assert.Equal(groups[0].Repo, LocalGroupRepo) // Local groups should come first
+}
@@ -0,0 +1,256 @@
+package pkgparse
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/candrewlee14/webman/utils"
+)
+
+// Hook is an action a recipe runs after installing or before removing a package version.
+// Exactly one of its fields should be set.
+type Hook struct {
+	// Run is a command and its arguments, run from the package version directory
+	Run []string `yaml:"run"`
+	// Completions saves the output of a command as shell completions for the package
+	Completions *CompletionsHook `yaml:"completions"`
+	// WriteFile writes a file, such as a config file
+	WriteFile *WriteFileHook `yaml:"write_file"`
+}
+
+// CompletionsHook generates shell completions
+type CompletionsHook struct {
+	// Shell is one of bash, zsh, fish or powershell
+	Shell string `yaml:"shell"`
+	// Run is a command that prints the completion script
+	Run []string `yaml:"run"`
+}
+
+// WriteFileHook writes a file
+type WriteFileHook struct {
+	// Path is where to write the file, relative to the package version directory unless absolute
+	Path    string `yaml:"path"`
+	Content string `yaml:"content"`
+	// Overwrite replaces an existing file instead of leaving it alone
+	Overwrite bool `yaml:"overwrite"`
+}
+
+// GeneratedCompletionsDir is the directory in a package version where generated completions are saved
+const GeneratedCompletionsDir = ".webman-completions"
+
+// CompletionFileName is the conventional completion file name of a command for a shell
+func CompletionFileName(cmd string, shell string) string {
+	switch shell {
+	case "zsh":
+		return "_" + cmd
+	case "fish":
+		return cmd + ".fish"
+	case "powershell":
+		return cmd + ".ps1"
+	default:
+		return cmd
+	}
+}
+
+// PostInstallHooks returns the package and then OS post-install hooks
+func (pkgConf *PkgConfig) PostInstallHooks() []Hook {
+	return append(append([]Hook{}, pkgConf.PostInstall...), pkgConf.MyOsInfo().PostInstall...)
+}
+
+// PreRemoveHooks returns the package and then OS pre-remove hooks
+func (pkgConf *PkgConfig) PreRemoveHooks() []Hook {
+	return append(append([]Hook{}, pkgConf.PreRemove...), pkgConf.MyOsInfo().PreRemove...)
+}
+
+// RunHooks runs hooks for an installed package version, logging their output one line at a time.
+// Hooks are skipped unless the repo the recipe came from allows them.
+func (pkgConf *PkgConfig) RunHooks(hooks []Hook, pkgVerStem string, logf func(format string, a ...any)) error {
+	if len(hooks) == 0 {
+		return nil
+	}
+	if !pkgConf.AllowHooks {
+		if utils.RecipeDirFlag != "" {
+			logf("Skipped %d hook(s); pass --allow-hooks to run hooks of local recipes", len(hooks))
+			return nil
+		}
+		logf("Skipped %d hook(s); set allow_hooks for repository %q in the config to run them", len(hooks), pkgConf.Repo)
+		return nil
+	}
+	// a broken hook shouldn't leave the hooks before it half applied
+	for _, hook := range hooks {
+		if err := hook.validate(); err != nil {
+			return err
+		}
+	}
+	pkgDir := filepath.Join(utils.WebmanPkgDir, pkgConf.Title, pkgVerStem)
+	_, ver := utils.ParseStem(pkgVerStem)
+	h := hookRunner{pkgConf: pkgConf, pkgDir: pkgDir, ver: ver, logf: logf}
+	for _, hook := range hooks {
+		var err error
+		switch {
+		case len(hook.Run) != 0:
+			err = h.run(hook.Run, nil)
+		case hook.Completions != nil:
+			err = h.completions(hook.Completions)
+		case hook.WriteFile != nil:
+			err = h.writeFile(hook.WriteFile)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validate checks that a hook has what it needs to run
+func (hook Hook) validate() error {
+	switch {
+	case len(hook.Run) != 0:
+		if hook.Run[0] == "" {
+			return errors.New("run hook has an empty command")
+		}
+	case hook.Completions != nil:
+		switch hook.Completions.Shell {
+		case "bash", "zsh", "fish", "powershell":
+		default:
+			return fmt.Errorf("unsupported completion shell %q", hook.Completions.Shell)
+		}
+		if len(hook.Completions.Run) == 0 || hook.Completions.Run[0] == "" {
+			return fmt.Errorf("%s completions hook has no command to run", hook.Completions.Shell)
+		}
+	case hook.WriteFile != nil:
+		if hook.WriteFile.Path == "" {
+			return errors.New("write_file hook has no path")
+		}
+	default:
+		return errors.New("empty hook")
+	}
+	return nil
+}
+
+type hookRunner struct {
+	pkgConf *PkgConfig
+	pkgDir  string
+	ver     string
+	logf    func(format string, a ...any)
+}
+
+// expand replaces the package variables in a hook value
+func (h hookRunner) expand(s string) string {
+	return h.pkgConf.pkgVars(h.pkgDir, h.ver).expand(s)
+}
+
+// path resolves a path relative to the package version directory
+func (h hookRunner) path(p string) string {
+	p = h.expand(p)
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(h.pkgDir, filepath.FromSlash(p))
+}
+
+// run runs a command, logging its output unless stdout is given
+func (h hookRunner) run(args []string, stdout io.Writer) error {
+	if len(args) == 0 || args[0] == "" {
+		return errors.New("hook has no command to run")
+	}
+	name := h.expand(args[0])
+	// commands from the package take precedence over commands on the PATH
+	for _, candidate := range []string{h.path(name), h.path(name) + ".exe"} {
+		if fi, err := os.Stat(candidate); err == nil && !fi.IsDir() {
+			name = candidate
+			break
+		}
+	}
+	cmdArgs := make([]string, 0, len(args)-1)
+	for _, arg := range args[1:] {
+		cmdArgs = append(cmdArgs, h.expand(arg))
+	}
+	cmd := exec.Command(name, cmdArgs...)
+	cmd.Dir = h.pkgDir
+	cmd.Env = append(os.Environ(), "WEBMAN_PKG_DIR="+h.pkgDir, "WEBMAN_PKG_VERSION="+h.ver)
+	output := &lineLogger{logf: h.logf}
+	cmd.Stdout = output
+	if stdout != nil {
+		cmd.Stdout = stdout
+	}
+	cmd.Stderr = output
+	h.logf("Running %s", strings.Join(args, " "))
+	err := cmd.Run()
+	output.flush()
+	if err != nil {
+		return fmt.Errorf("hook %q failed: %v", strings.Join(args, " "), err)
+	}
+	return nil
+}
+
+func (h hookRunner) completions(hook *CompletionsHook) error {
+	var script bytes.Buffer
+	if err := h.run(hook.Run, &script); err != nil {
+		return err
+	}
+	dir := filepath.Join(h.pkgDir, GeneratedCompletionsDir, hook.Shell)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, CompletionFileName(h.pkgConf.Title, hook.Shell)), script.Bytes(), 0o644); err != nil {
+		return err
+	}
+	h.logf("Generated %s completions", hook.Shell)
+	return nil
+}
+
+func (h hookRunner) writeFile(hook *WriteFileHook) error {
+	path := h.path(hook.Path)
+	if !hook.Overwrite {
+		if _, err := os.Stat(path); err == nil {
+			h.logf("Leaving existing %s", path)
+			return nil
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(h.expand(hook.Content)), 0o644); err != nil {
+		return err
+	}
+	h.logf("Wrote %s", path)
+	return nil
+}
+
+// lineLogger logs written output one line at a time
+type lineLogger struct {
+	logf    func(format string, a ...any)
+	partial []byte
+}
+
+func (l *lineLogger) Write(p []byte) (int, error) {
+	l.partial = append(l.partial, p...)
+	for {
+		i := bytes.IndexByte(l.partial, '\n')
+		if i < 0 {
+			break
+		}
+		if text := strings.TrimSpace(string(l.partial[:i])); text != "" {
+			l.logf("%s", text)
+		}
+		l.partial = l.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+func (l *lineLogger) flush() {
+	if text := strings.TrimSpace(string(l.partial)); text != "" {
+		l.logf("%s", text)
+	}
+	l.partial = nil
+}
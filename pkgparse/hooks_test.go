@@ -0,0 +1,65 @@
+package pkgparse
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/candrewlee14/webman/utils"
+
+	"github.com/matryer/is"
+)
+
+func TestRunHooks(t *testing.T) {
+	assert := is.New(t)
+
+	tmp := t.TempDir()
+	utils.Init(tmp)
+	pkgDir := filepath.Join(utils.WebmanPkgDir, "foo", "foo-1.2.0")
+	assert.NoErr(os.MkdirAll(pkgDir, os.ModePerm)) // Should create version dir
+
+	pkgConf := &PkgConfig{Title: "foo", Repo: "webman"}
+	hooks := []Hook{{WriteFile: &WriteFileHook{Path: "conf/foo.toml", Content: "root = \"[PKG_DIR]\"\nversion = \"[VER]\"\n"}}}
+	var logs []string
+	logf := func(format string, a ...any) {
+		logs = append(logs, format)
+	}
+
+	assert.NoErr(pkgConf.RunHooks(hooks, "foo-1.2.0", logf)) // Should skip disallowed hooks
+	_, err := os.Stat(filepath.Join(pkgDir, "conf", "foo.toml"))
+	assert.True(os.IsNotExist(err)) // Disallowed hook should not write files
+	assert.Equal(len(logs), 1)      // Skipped hooks should be reported
+
+	pkgConf.AllowHooks = true
+	assert.NoErr(pkgConf.RunHooks(hooks, "foo-1.2.0", logf)) // Should run allowed hooks
+	data, err := os.ReadFile(filepath.Join(pkgDir, "conf", "foo.toml"))
+	assert.NoErr(err)                                                          // Hook should write file
+	assert.Equal(string(data), "root = \""+pkgDir+"\"\nversion = \"1.2.0\"\n") // Hook variables should be expanded
+
+	invalid := [][]Hook{
+		{{}},
+		{{Run: []string{""}}},
+		{{Completions: &CompletionsHook{Shell: "zsh"}}},
+		{{Completions: &CompletionsHook{Shell: "tcsh", Run: []string{"foo", "completions"}}}},
+		{{WriteFile: &WriteFileHook{Content: "foo"}}},
+		{{WriteFile: &WriteFileHook{Path: "ran", Content: "foo"}}, {Completions: &CompletionsHook{Shell: "bash", Run: []string{}}}},
+	}
+	for _, hooks := range invalid {
+		assert.True(pkgConf.RunHooks(hooks, "foo-1.2.0", logf) != nil) // Invalid hook should be an error
+	}
+	_, err = os.Stat(filepath.Join(pkgDir, "ran"))
+	assert.True(os.IsNotExist(err)) // Hooks before an invalid hook should not run
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	hooks = []Hook{{Completions: &CompletionsHook{Shell: "zsh", Run: []string{"sh", "-c", "echo compdef _foo foo"}}}}
+	assert.NoErr(pkgConf.RunHooks(hooks, "foo-1.2.0", logf)) // Should generate completions
+	data, err = os.ReadFile(filepath.Join(pkgDir, GeneratedCompletionsDir, "zsh", "_foo"))
+	assert.NoErr(err)                                // Completions should be saved
+	assert.Equal(string(data), "compdef _foo foo\n") // Completions should be the command output
+
+	hooks = []Hook{{Run: []string{"sh", "-c", "exit 3"}}}
+	assert.True(pkgConf.RunHooks(hooks, "foo-1.2.0", logf) != nil) // Failing command should be reported
+}
@@ -0,0 +1,294 @@
+package pkgparse
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/candrewlee14/webman/config"
+	"github.com/candrewlee14/webman/utils"
+)
+
+// PkgIndexEntry is a summary of a package recipe in the recipe index
+type PkgIndexEntry struct {
+	Title          string            `json:"title"`
+	Tagline        string            `json:"tagline"`
+	About          string            `json:"about"`
+	InstallNote    string            `json:"install_note,omitempty"`
+	OsInstallNotes map[string]string `json:"os_install_notes,omitempty"`
+	Platforms      []OsArchPair      `json:"platforms"`
+	Repo           string            `json:"repo"`
+	Hash           string            `json:"hash"`
+}
+
+// InstallNotes combines package-level and OS-level installation notes, like PkgConfig.InstallNotes
+func (entry *PkgIndexEntry) InstallNotes() string {
+	pkgConf := PkgConfig{
+		Title:       entry.Title,
+		InstallNote: entry.InstallNote,
+		OsMap:       make(map[string]OsInfo),
+	}
+	for pkgOs, note := range entry.OsInstallNotes {
+		pkgConf.OsMap[pkgOs] = OsInfo{InstallNote: note}
+	}
+	return pkgConf.InstallNotes()
+}
+
+// GroupIndexEntry is a summary of a package group in the recipe index
+type GroupIndexEntry struct {
+	Name        string   `json:"name"`
+	Title       string   `json:"title"`
+	Tagline     string   `json:"tagline"`
+	Description string   `json:"description"`
+	Packages    []string `json:"packages"`
+	// Groups are the groups included in this group
+	Groups []string `json:"groups,omitempty"`
+	Repo   string   `json:"repo"`
+	Hash   string   `json:"hash"`
+}
+
+// RepoIndex is the index of a single package repository
+type RepoIndex struct {
+	// ModTime is the modification time of the repository directory when it was indexed
+	ModTime time.Time         `json:"mod_time"`
+	Pkgs    []PkgIndexEntry   `json:"pkgs"`
+	Groups  []GroupIndexEntry `json:"groups"`
+}
+
+// Index is an index of the recipes in all package repositories,
+// so that searching doesn't need to parse every recipe
+type Index struct {
+	Repos map[string]*RepoIndex `json:"repos"`
+
+	order []string
+	// local are the groups in ~/.webman/groups
+	local []GroupIndexEntry
+}
+
+// Pkgs returns all indexed packages, in repository order
+func (idx *Index) Pkgs() []PkgIndexEntry {
+	var pkgs []PkgIndexEntry
+	for _, repo := range idx.order {
+		pkgs = append(pkgs, idx.Repos[repo].Pkgs...)
+	}
+	return pkgs
+}
+
+// Groups returns all local groups followed by all indexed groups, in repository order
+func (idx *Index) Groups() []GroupIndexEntry {
+	groups := append([]GroupIndexEntry{}, idx.local...)
+	for _, repo := range idx.order {
+		groups = append(groups, idx.Repos[repo].Groups...)
+	}
+	return groups
+}
+
+// FindPkg returns the first indexed package with the given name, or nil if none exists
+func (idx *Index) FindPkg(pkg string) *PkgIndexEntry {
+	for _, repo := range idx.order {
+		for i, entry := range idx.Repos[repo].Pkgs {
+			if entry.Title == pkg {
+				return &idx.Repos[repo].Pkgs[i]
+			}
+		}
+	}
+	return nil
+}
+
+func indexPath() string {
+	return filepath.Join(utils.WebmanRecipeDir, utils.RecipeIndexFileName)
+}
+
+// LoadIndex loads the recipe index for the given repos.
+// Repos that are missing from the index or have changed since they were indexed are re-indexed.
+func LoadIndex(pkgRepos []*config.PkgRepo) (*Index, error) {
+	idx := &Index{Repos: make(map[string]*RepoIndex)}
+	// local recipes are edited in place, so always index them fresh
+	local := utils.RecipeDirFlag != ""
+	if !local {
+		data, err := os.ReadFile(indexPath())
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		if err == nil {
+			// a corrupt index is rebuilt from scratch
+			if err := json.Unmarshal(data, idx); err != nil || idx.Repos == nil {
+				idx.Repos = make(map[string]*RepoIndex)
+			}
+		}
+	}
+	changed := false
+	repos := make(map[string]*RepoIndex, len(pkgRepos))
+	for _, pkgRepo := range config.ByPriority(pkgRepos) {
+		fi, err := os.Stat(pkgRepo.Path())
+		if err != nil {
+			// repos whose recipes were never fetched have nothing to index
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		repoIdx, ok := idx.Repos[pkgRepo.Name]
+		if !ok || !repoIdx.ModTime.Equal(fi.ModTime()) {
+			if repoIdx, err = buildRepoIndex(pkgRepo); err != nil {
+				return nil, err
+			}
+			changed = true
+		}
+		repos[pkgRepo.Name] = repoIdx
+		idx.order = append(idx.order, pkgRepo.Name)
+	}
+	if len(repos) != len(idx.Repos) {
+		changed = true
+	}
+	idx.Repos = repos
+	if changed && !local {
+		if err := idx.save(); err != nil {
+			return nil, err
+		}
+	}
+	localGroups, err := LocalGroups()
+	if err != nil {
+		return nil, err
+	}
+	idx.local = localGroups
+	return idx, nil
+}
+
+// IndexRepo rebuilds the index entry for a single package repository
+func IndexRepo(pkgRepo *config.PkgRepo) error {
+	idx := &Index{Repos: make(map[string]*RepoIndex)}
+	data, err := os.ReadFile(indexPath())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, idx); err != nil || idx.Repos == nil {
+			idx.Repos = make(map[string]*RepoIndex)
+		}
+	}
+	repoIdx, err := buildRepoIndex(pkgRepo)
+	if err != nil {
+		return err
+	}
+	idx.Repos[pkgRepo.Name] = repoIdx
+	return idx.save()
+}
+
+// ApplyRecipes replaces the recipes for a PkgRepo with staged ones and re-indexes them
+func ApplyRecipes(pkgRepo *config.PkgRepo, stagedPath string) error {
+	if err := pkgRepo.ApplyRecipes(stagedPath); err != nil {
+		return err
+	}
+	return IndexRepo(pkgRepo)
+}
+
+func (idx *Index) save() error {
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	tmpFile, err := os.CreateTemp(utils.WebmanRecipeDir, utils.RecipeIndexFileName+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile.Name(), indexPath())
+}
+
+func buildRepoIndex(pkgRepo *config.PkgRepo) (*RepoIndex, error) {
+	fi, err := os.Stat(pkgRepo.Path())
+	if err != nil {
+		return nil, err
+	}
+	repoIdx := &RepoIndex{
+		ModTime: fi.ModTime(),
+		Pkgs:    []PkgIndexEntry{},
+		Groups:  []GroupIndexEntry{},
+	}
+	pkgFiles, err := os.ReadDir(pkgRepo.PackagePath())
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range pkgFiles {
+		if !strings.HasSuffix(file.Name(), utils.PkgRecipeExt) {
+			continue
+		}
+		pkg := strings.TrimSuffix(file.Name(), utils.PkgRecipeExt)
+		data, err := os.ReadFile(filepath.Join(pkgRepo.PackagePath(), file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		pkgConf, err := ParsePkgConfig(pkg, bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		entry := PkgIndexEntry{
+			Title:       pkgConf.Title,
+			Tagline:     pkgConf.Tagline,
+			About:       pkgConf.About,
+			InstallNote: pkgConf.InstallNote,
+			Platforms:   pkgConf.SupportedPlatforms(),
+			Repo:        pkgRepo.Name,
+			Hash:        hashBytes(data),
+		}
+		for pkgOs, osInfo := range pkgConf.OsMap {
+			if osInfo.InstallNote != "" {
+				if entry.OsInstallNotes == nil {
+					entry.OsInstallNotes = make(map[string]string)
+				}
+				entry.OsInstallNotes[pkgOs] = osInfo.InstallNote
+			}
+		}
+		repoIdx.Pkgs = append(repoIdx.Pkgs, entry)
+	}
+
+	groupFiles, err := os.ReadDir(pkgRepo.GroupPath())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	for _, file := range groupFiles {
+		if !strings.HasSuffix(file.Name(), utils.GroupRecipeExt) {
+			continue
+		}
+		group := strings.TrimSuffix(file.Name(), utils.GroupRecipeExt)
+		data, err := os.ReadFile(filepath.Join(pkgRepo.GroupPath(), file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		groupConf, err := ParseGroupConfig(bytes.NewReader(data), group)
+		if err != nil {
+			return nil, err
+		}
+		repoIdx.Groups = append(repoIdx.Groups, GroupIndexEntry{
+			Name:        group,
+			Title:       groupConf.Title,
+			Tagline:     groupConf.Tagline,
+			Description: groupConf.Description,
+			Packages:    groupConf.PackageArgs(),
+			Groups:      groupConf.Groups,
+			Repo:        pkgRepo.Name,
+			Hash:        hashBytes(data),
+		})
+	}
+	return repoIdx, nil
+}
+
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
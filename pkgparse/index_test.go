@@ -0,0 +1,65 @@
+package pkgparse
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/candrewlee14/webman/config"
+	"github.com/candrewlee14/webman/utils"
+
+	"github.com/matryer/is"
+)
+
+func TestLoadIndex(t *testing.T) {
+	assert := is.New(t)
+
+	tmp := t.TempDir()
+	utils.Init(tmp)
+	pkgRepo := &config.PkgRepo{Name: "test"}
+	writeRecipe := func(pkg string) {
+		assert.NoErr(os.MkdirAll(pkgRepo.PackagePath(), os.ModePerm))                                        // Should create pkgs dir
+		err := os.WriteFile(filepath.Join(pkgRepo.PackagePath(), pkg+utils.PkgRecipeExt), testRecipe, 0o644) // Should write recipe
+		assert.NoErr(err)
+	}
+	writeRecipe("foo")
+
+	idx, err := LoadIndex([]*config.PkgRepo{pkgRepo})
+	assert.NoErr(err)                // Should build index
+	assert.Equal(len(idx.Pkgs()), 1) // Should index a single package
+	entry := idx.FindPkg("foo")
+	assert.True(entry != nil)                                                                               // Should find indexed package
+	assert.Equal(entry.Repo, "test")                                                                        // Should record the repo
+	assert.Equal(entry.Platforms, []OsArchPair{{Os: "linux", Arch: "amd64"}, {Os: "macos", Arch: "amd64"}}) // Should list supported platforms
+
+	_, err = os.Stat(filepath.Join(utils.WebmanRecipeDir, utils.RecipeIndexFileName))
+	assert.NoErr(err) // Index should be saved
+
+	// replacing the repo directory, like a refresh, should invalidate the index
+	assert.NoErr(os.RemoveAll(pkgRepo.Path())) // Should remove repo
+	writeRecipe("bar")
+	writeRecipe("baz")
+
+	idx, err = LoadIndex([]*config.PkgRepo{pkgRepo})
+	assert.NoErr(err)                      // Should load index
+	assert.Equal(len(idx.Pkgs()), 2)       // Should re-index the changed repo
+	assert.True(idx.FindPkg("foo") == nil) // Removed package should no longer be indexed
+}
+
+var testRecipe = []byte(`tagline: A test package
+about: Used for testing the recipe index
+base_download_url: https://example.com/[VER]/
+filename_format: foo-[OS]-[ARCH]
+latest_strategy: github-release
+git_user: example
+git_repo: foo
+os_map:
+  linux:
+    name: linux
+    is_raw_binary: true
+  macos:
+    name: darwin
+    is_raw_binary: true
+arch_map:
+  amd64: x86_64
+`)
@@ -0,0 +1,222 @@
+package pkgparse
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/candrewlee14/webman/config"
+	"github.com/candrewlee14/webman/ui"
+	"github.com/candrewlee14/webman/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// InstallInfo is what is recorded about an installed package version
+type InstallInfo struct {
+	Repo string `yaml:"repo"`
+}
+
+// installedFile is the record of all installed versions of a package, keyed by version stem
+type installedFile struct {
+	Versions map[string]InstallInfo `yaml:"versions"`
+	// Held packages are skipped by upgrades
+	Held bool `yaml:"held,omitempty"`
+}
+
+func installedPath(pkg string) string {
+	return filepath.Join(utils.WebmanPkgDir, pkg, utils.InstalledFileName)
+}
+
+func readInstalled(pkg string) (*installedFile, error) {
+	installed := installedFile{Versions: make(map[string]InstallInfo)}
+	data, err := os.ReadFile(installedPath(pkg))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return &installed, nil
+		}
+		return nil, err
+	}
+	if err = yaml.Unmarshal(data, &installed); err != nil {
+		return nil, err
+	}
+	if installed.Versions == nil {
+		installed.Versions = make(map[string]InstallInfo)
+	}
+	return &installed, nil
+}
+
+func writeInstalled(pkg string, installed *installedFile) error {
+	data, err := yaml.Marshal(installed)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(installedPath(pkg), data, os.ModePerm)
+}
+
+// CheckInstalled returns the recorded information for an installed package version stem.
+// If nothing was recorded (such as for versions installed by older webman versions), it returns nil.
+func CheckInstalled(pkg string, pkgVerStem string) (*InstallInfo, error) {
+	installed, err := readInstalled(pkg)
+	if err != nil {
+		return nil, err
+	}
+	info, ok := installed.Versions[pkgVerStem]
+	if !ok {
+		return nil, nil
+	}
+	return &info, nil
+}
+
+// WriteInstalled records information for an installed package version stem
+func WriteInstalled(pkg string, pkgVerStem string, info InstallInfo) error {
+	installed, err := readInstalled(pkg)
+	if err != nil {
+		return err
+	}
+	installed.Versions[pkgVerStem] = info
+	return writeInstalled(pkg, installed)
+}
+
+// RemoveInstalled removes the recorded information for a package version stem
+func RemoveInstalled(pkg string, pkgVerStem string) error {
+	installed, err := readInstalled(pkg)
+	if err != nil {
+		return err
+	}
+	if _, ok := installed.Versions[pkgVerStem]; !ok {
+		return nil
+	}
+	delete(installed.Versions, pkgVerStem)
+	if err := os.Remove(savedRecipePath(pkg, pkgVerStem)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return writeInstalled(pkg, installed)
+}
+
+// savedRecipePath is where the recipe a package version was installed with is kept
+func savedRecipePath(pkg string, pkgVerStem string) string {
+	return filepath.Join(utils.WebmanPkgDir, pkg, pkgVerStem+utils.PkgRecipeExt)
+}
+
+// SaveRecipe keeps a copy of the recipe a package version was installed with, unless one is already kept,
+// so the version can still be switched to and removed after the recipe is gone from its repo
+func SaveRecipe(pkgConf *PkgConfig, pkgVerStem string) error {
+	if pkgConf.RecipePath == "" {
+		return nil
+	}
+	path := savedRecipePath(pkgConf.Title, pkgVerStem)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	data, err := os.ReadFile(pkgConf.RecipePath)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, os.ModePerm)
+}
+
+// ParseSavedPkgConfig parses the recipe kept when a package version was installed
+func ParseSavedPkgConfig(pkg string, pkgVerStem string) (*PkgConfig, error) {
+	fi, err := os.Open(savedRecipePath(pkg, pkgVerStem))
+	if err != nil {
+		return nil, err
+	}
+	defer fi.Close()
+	return ParsePkgConfig(pkg, fi)
+}
+
+// InstalledFromRepo returns the installed packages with a version installed from the given repo
+func InstalledFromRepo(pkgRepos []*config.PkgRepo, repo string) []string {
+	var pkgs []string
+	for _, pkg := range utils.InstalledPackages() {
+		if stems, err := InstalledStemsFromRepo(pkgRepos, pkg, repo); err == nil && len(stems) != 0 {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	return pkgs
+}
+
+// InstalledStemsFromRepo returns the installed version stems of a package that were installed from the given repo,
+// sorted oldest to newest. Versions installed before their repo was recorded are included
+// if the given repo is the only one with a recipe for the package.
+func InstalledStemsFromRepo(pkgRepos []*config.PkgRepo, pkg string, repo string) ([]string, error) {
+	installed, err := readInstalled(pkg)
+	if err != nil {
+		return nil, err
+	}
+	stems, err := utils.InstalledPkgVerStems(pkg)
+	if err != nil {
+		return nil, err
+	}
+	var onlyInRepo *bool
+	var fromRepo []string
+	for _, stem := range stems {
+		if info, ok := installed.Versions[stem]; ok {
+			if info.Repo == repo {
+				fromRepo = append(fromRepo, stem)
+			}
+			continue
+		}
+		if onlyInRepo == nil {
+			found, err := FindPkgRepos(pkgRepos, pkg)
+			if err != nil {
+				return nil, err
+			}
+			only := len(found) == 1 && found[0].Name == repo
+			onlyInRepo = &only
+		}
+		if *onlyInRepo {
+			fromRepo = append(fromRepo, stem)
+		}
+	}
+	return fromRepo, nil
+}
+
+// IsHeld determines whether a package is held at its installed versions
+func IsHeld(pkg string) (bool, error) {
+	installed, err := readInstalled(pkg)
+	if err != nil {
+		return false, err
+	}
+	return installed.Held, nil
+}
+
+// SetHeld holds or releases an installed package
+func SetHeld(pkg string, held bool) error {
+	if _, err := os.Stat(filepath.Join(utils.WebmanPkgDir, pkg)); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return ui.Errorf(ui.CodeNotInstalled, "%s is not installed", pkg)
+		}
+		return err
+	}
+	installed, err := readInstalled(pkg)
+	if err != nil {
+		return err
+	}
+	installed.Held = held
+	return writeInstalled(pkg, installed)
+}
+
+// InstalledDependents returns the installed packages whose recipes depend on pkg.
+// Packages whose recipes can't be found are skipped.
+func InstalledDependents(pkgRepos []*config.PkgRepo, pkg string) []string {
+	var dependents []string
+	for _, installedPkg := range utils.InstalledPackages() {
+		if installedPkg == pkg {
+			continue
+		}
+		pkgConf, err := ParseActivePkgConfig(pkgRepos, installedPkg)
+		if err != nil {
+			continue
+		}
+		for _, dependArg := range pkgConf.Depends {
+			if _, depPkg, _, err := utils.ParsePkgVer(dependArg); err == nil && depPkg == pkg {
+				dependents = append(dependents, installedPkg)
+				break
+			}
+		}
+	}
+	return dependents
+}
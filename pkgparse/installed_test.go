@@ -0,0 +1,151 @@
+package pkgparse
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/candrewlee14/webman/config"
+	"github.com/candrewlee14/webman/ui"
+	"github.com/candrewlee14/webman/utils"
+
+	"github.com/matryer/is"
+)
+
+func TestParseInstalledPkgConfig(t *testing.T) {
+	assert := is.New(t)
+
+	tmp := t.TempDir()
+	utils.Init(tmp)
+	var pkgRepos []*config.PkgRepo
+	for _, name := range []string{"high", "low"} {
+		pkgRepo := &config.PkgRepo{Name: name}
+		assert.NoErr(os.MkdirAll(pkgRepo.PackagePath(), os.ModePerm))                                          // Should create pkgs dir
+		err := os.WriteFile(filepath.Join(pkgRepo.PackagePath(), "foo"+utils.PkgRecipeExt), testRecipe, 0o644) // Should write recipe
+		assert.NoErr(err)
+		pkgRepos = append(pkgRepos, pkgRepo)
+	}
+	assert.NoErr(os.MkdirAll(filepath.Join(utils.WebmanPkgDir, "foo"), os.ModePerm)) // Should create package dir
+
+	found, err := FindPkgRepos(pkgRepos, "foo")
+	assert.NoErr(err)           // Should find providers
+	assert.Equal(len(found), 2) // Should find both repos
+
+	pkgConf, err := ParseInstalledPkgConfig(pkgRepos, "foo", "foo-1.0.0")
+	assert.NoErr(err)                  // Should parse without a recorded repo
+	assert.Equal(pkgConf.Repo, "high") // Should fall back to the highest priority repo
+
+	assert.NoErr(WriteInstalled("foo", "foo-1.0.0", InstallInfo{Repo: "low"})) // Should record installed repo
+	pkgConf, err = ParseInstalledPkgConfig(pkgRepos, "foo", "foo-1.0.0")
+	assert.NoErr(err)                 // Should parse with a recorded repo
+	assert.Equal(pkgConf.Repo, "low") // Should use the repo it was installed from
+
+	assert.NoErr(RemoveInstalled("foo", "foo-1.0.0")) // Should remove installed record
+	info, err := CheckInstalled("foo", "foo-1.0.0")
+	assert.NoErr(err)        // Should read installed records
+	assert.True(info == nil) // Should have no record after removal
+}
+
+func TestSavedRecipe(t *testing.T) {
+	assert := is.New(t)
+
+	tmp := t.TempDir()
+	utils.Init(tmp)
+	pkgRepo := &config.PkgRepo{Name: "webman"}
+	pkgRepos := []*config.PkgRepo{pkgRepo}
+	recipePath := filepath.Join(pkgRepo.PackagePath(), "foo"+utils.PkgRecipeExt)
+	assert.NoErr(os.MkdirAll(pkgRepo.PackagePath(), os.ModePerm))                                 // Should create pkgs dir
+	assert.NoErr(os.WriteFile(recipePath, testRecipe, 0o644))                                     // Should write recipe
+	assert.NoErr(os.MkdirAll(filepath.Join(utils.WebmanPkgDir, "foo", "foo-1.0.0"), os.ModePerm)) // Should create version dir
+
+	pkgConf, err := ParsePkgConfigLocal(pkgRepos, "foo")
+	assert.NoErr(err)                                                             // Should parse recipe
+	assert.NoErr(WriteInstalled("foo", "foo-1.0.0", InstallInfo{Repo: "webman"})) // Should record installed repo
+	assert.NoErr(SaveRecipe(pkgConf, "foo-1.0.0"))                                // Should keep recipe
+	assert.NoErr(os.Remove(recipePath))                                           // Should delete recipe from repo
+
+	_, err = ParsePkgConfigLocal(pkgRepos, "foo")
+	assert.True(err != nil) // Recipe should be gone from repo
+	pkgConf, err = ParseInstalledPkgConfig(pkgRepos, "foo", "foo-1.0.0")
+	assert.NoErr(err)                    // Should parse kept recipe
+	assert.Equal(pkgConf.Repo, "webman") // Kept recipe should remember its repo
+	assert.True(!pkgConf.AllowHooks)     // Kept recipe should not run hooks
+	pkgConf, err = ParseActivePkgConfig(pkgRepos, "foo")
+	assert.NoErr(err)                                                    // Should parse kept recipe of newest version
+	assert.Equal(pkgConf.Title, "foo")                                   // Should parse the package's recipe
+	assert.Equal(InstalledFromRepo(pkgRepos, "webman"), []string{"foo"}) // Should find package installed from repo
+
+	other := &config.PkgRepo{Name: "other", AllowHooks: true}
+	otherRecipe := strings.Replace(string(testRecipe), "tagline: A test package", "tagline: An unrelated package", 1)
+	assert.NoErr(os.MkdirAll(other.PackagePath(), os.ModePerm))                                                          // Should create other pkgs dir
+	assert.NoErr(os.WriteFile(filepath.Join(other.PackagePath(), "foo"+utils.PkgRecipeExt), []byte(otherRecipe), 0o644)) // Should write unrelated recipe
+	pkgConf, err = ParseInstalledPkgConfig([]*config.PkgRepo{other}, "foo", "foo-1.0.0")
+	assert.NoErr(err)                               // Should parse kept recipe when its repo is gone
+	assert.Equal(pkgConf.Tagline, "A test package") // Kept recipe should win over another repo's recipe with the same name
+	assert.True(!pkgConf.AllowHooks)                // Another repo's hook setting should not apply
+
+	_, err = ParseInstalledPkgConfig(pkgRepos, "foo", "foo-2.0.0")
+	assert.Equal(ui.CodeOf(err), ui.CodeRecipeNotFound) // Version without a kept recipe should not be found
+
+	assert.NoErr(RemoveInstalled("foo", "foo-1.0.0")) // Should remove installed record
+	_, err = ParseInstalledPkgConfig(pkgRepos, "foo", "foo-1.0.0")
+	assert.Equal(ui.CodeOf(err), ui.CodeRecipeNotFound) // Kept recipe should be removed with the record
+}
+
+func TestSetHeld(t *testing.T) {
+	assert := is.New(t)
+
+	tmp := t.TempDir()
+	utils.Init(tmp)
+	assert.True(SetHeld("foo", true) != nil) // Should not hold a package that isn't installed
+
+	assert.NoErr(os.MkdirAll(filepath.Join(utils.WebmanPkgDir, "foo", "foo-1.0.0"), os.ModePerm)) // Should create version dir
+	assert.NoErr(WriteInstalled("foo", "foo-1.0.0", InstallInfo{Repo: "webman"}))                 // Should record installed repo
+	assert.NoErr(SetHeld("foo", true))                                                            // Should hold package
+	held, err := IsHeld("foo")
+	assert.NoErr(err) // Should read hold
+	assert.True(held) // Package should be held
+	info, err := CheckInstalled("foo", "foo-1.0.0")
+	assert.NoErr(err)                 // Should read installed records
+	assert.Equal(info.Repo, "webman") // Holding should keep installed records
+
+	assert.NoErr(SetHeld("foo", false)) // Should release package
+	held, err = IsHeld("foo")
+	assert.NoErr(err)  // Should read hold
+	assert.True(!held) // Package should be released
+}
+
+func TestInstalledStemsFromRepo(t *testing.T) {
+	assert := is.New(t)
+
+	utils.Init(t.TempDir())
+	for stem, repo := range map[string]string{"foo-1.0.0": "webman", "foo-1.1.0": "other", "foo-1.2.0": "webman"} {
+		assert.NoErr(os.MkdirAll(filepath.Join(utils.WebmanPkgDir, "foo", stem), os.ModePerm)) // Should create version dir
+		assert.NoErr(WriteInstalled("foo", stem, InstallInfo{Repo: repo}))                     // Should record installed repo
+	}
+	assert.NoErr(WriteInstalled("foo", "foo-0.9.0", InstallInfo{Repo: "webman"})) // Should record a version whose dir is gone
+
+	stems, err := InstalledStemsFromRepo(nil, "foo", "webman")
+	assert.NoErr(err)                                              // Should list versions from repo
+	assert.Equal(stems, []string{"foo-1.0.0", "foo-1.2.0"})        // Should only list installed versions from the repo
+	assert.Equal(InstalledFromRepo(nil, "other"), []string{"foo"}) // Should find package with a version from the other repo
+	assert.Equal(len(InstalledFromRepo(nil, "missing")), 0)        // Should find no packages from an unknown repo
+
+	webman := &config.PkgRepo{Name: "webman"}
+	other := &config.PkgRepo{Name: "other"}
+	pkgRepos := []*config.PkgRepo{webman, other}
+	assert.NoErr(os.MkdirAll(filepath.Join(utils.WebmanPkgDir, "foo", "foo-0.5.0"), os.ModePerm))                // Should create unrecorded version dir
+	assert.NoErr(os.MkdirAll(webman.PackagePath(), os.ModePerm))                                                 // Should create pkgs dir
+	assert.NoErr(os.WriteFile(filepath.Join(webman.PackagePath(), "foo"+utils.PkgRecipeExt), testRecipe, 0o644)) // Should write recipe
+	stems, err = InstalledStemsFromRepo(pkgRepos, "foo", "webman")
+	assert.NoErr(err)                                                    // Should list versions from repo
+	assert.Equal(stems, []string{"foo-0.5.0", "foo-1.0.0", "foo-1.2.0"}) // Unrecorded version should belong to the only repo with its recipe
+	assert.Equal(InstalledFromRepo(pkgRepos, "webman"), []string{"foo"}) // Should find package by its unrecorded version
+
+	assert.NoErr(os.MkdirAll(other.PackagePath(), os.ModePerm))                                                 // Should create other pkgs dir
+	assert.NoErr(os.WriteFile(filepath.Join(other.PackagePath(), "foo"+utils.PkgRecipeExt), testRecipe, 0o644)) // Should write recipe
+	stems, err = InstalledStemsFromRepo(pkgRepos, "foo", "webman")
+	assert.NoErr(err)                                       // Should list versions from repo
+	assert.Equal(stems, []string{"foo-1.0.0", "foo-1.2.0"}) // Unrecorded version should not be claimed when several repos have the recipe
+}
@@ -0,0 +1,101 @@
+package pkgparse
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/candrewlee14/webman/config"
+	"github.com/candrewlee14/webman/schema"
+	"github.com/candrewlee14/webman/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LocalGroupRepo is the repo name of groups defined in ~/.webman/groups
+const LocalGroupRepo = config.LocalRepoName
+
+var groupNameExp = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidateGroupName checks that a group name can be used as a file name
+func ValidateGroupName(group string) error {
+	if !groupNameExp.MatchString(group) {
+		return fmt.Errorf("invalid group name %q: use letters, digits, '.', '_' and '-'", group)
+	}
+	return nil
+}
+
+// LocalGroupPath is the filepath to a local group
+func LocalGroupPath(group string) string {
+	return filepath.Join(utils.WebmanGroupDir, group+utils.GroupRecipeExt)
+}
+
+// LintGroupData checks that group file contents match the group schema and can be parsed
+func LintGroupData(group string, data []byte) error {
+	if err := schema.LintGroup(bytes.NewReader(data)); err != nil {
+		return err
+	}
+	_, err := ParseGroupConfig(bytes.NewReader(data), group)
+	return err
+}
+
+// WriteLocalGroup validates and saves a local group
+func WriteLocalGroup(group string, groupConf *PkgGroupConfig) error {
+	if err := ValidateGroupName(group); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(groupConf)
+	if err != nil {
+		return err
+	}
+	if err := LintGroupData(group, data); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(utils.WebmanGroupDir, os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(LocalGroupPath(group), data, 0o644)
+}
+
+// LocalGroups returns index entries for all local groups.
+// Local groups are edited in place, so they are read fresh instead of being cached in the index.
+func LocalGroups() ([]GroupIndexEntry, error) {
+	files, err := os.ReadDir(utils.WebmanGroupDir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var groups []GroupIndexEntry
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), utils.GroupRecipeExt) {
+			continue
+		}
+		group := strings.TrimSuffix(file.Name(), utils.GroupRecipeExt)
+		data, err := os.ReadFile(filepath.Join(utils.WebmanGroupDir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		groupConf, err := ParseGroupConfig(bytes.NewReader(data), group)
+		if err != nil {
+			return nil, fmt.Errorf("local group %s: %v", group, err)
+		}
+		groups = append(groups, GroupIndexEntry{
+			Name:        group,
+			Title:       groupConf.Title,
+			Tagline:     groupConf.Tagline,
+			Description: groupConf.Description,
+			Packages:    groupConf.PackageArgs(),
+			Groups:      groupConf.Groups,
+			Repo:        LocalGroupRepo,
+			Hash:        hashBytes(data),
+		})
+	}
+	return groups, nil
+}
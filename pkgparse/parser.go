@@ -8,9 +8,11 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/candrewlee14/webman/config"
+	"github.com/candrewlee14/webman/ui"
 	"github.com/candrewlee14/webman/utils"
 
 	"github.com/fatih/color"
@@ -25,21 +27,31 @@ type RenameItem struct {
 
 // OsInfo is specific information for a package based on OS
 type OsInfo struct {
-	Name                   string        `yaml:"name"`
-	Ext                    string        `yaml:"ext"`
-	BinPaths               SingleOrMulti `yaml:"bin_path"`
-	ExtractHasRoot         bool          `yaml:"extract_has_root"`
-	IsRawBinary            bool          `yaml:"is_raw_binary"`
-	FilenameFormatOverride string        `yaml:"filename_format_override"`
-	Renames                []RenameItem  `yaml:"renames"`
-	InstallNote            string        `yaml:"install_note"`
-	RemoveNote             string        `yaml:"remove_note"`
+	Name                   string            `yaml:"name"`
+	Ext                    string            `yaml:"ext"`
+	BinPaths               SingleOrMulti     `yaml:"bin_path"`
+	ExtractHasRoot         bool              `yaml:"extract_has_root"`
+	IsRawBinary            bool              `yaml:"is_raw_binary"`
+	FilenameFormatOverride string            `yaml:"filename_format_override"`
+	Renames                []RenameItem      `yaml:"renames"`
+	InstallNote            string            `yaml:"install_note"`
+	RemoveNote             string            `yaml:"remove_note"`
+	PostInstall            []Hook            `yaml:"post_install"`
+	PreRemove              []Hook            `yaml:"pre_remove"`
+	Env                    map[string]string `yaml:"env"`
+	Completions            map[string]string `yaml:"completions"`
+	Man                    SingleOrMulti     `yaml:"man"`
 }
 
 // OsArchPair is a mapping of OS to ARCH
 type OsArchPair struct {
-	Os   string `yaml:"os"`
-	Arch string `yaml:"arch"`
+	Os   string `yaml:"os" json:"os"`
+	Arch string `yaml:"arch" json:"arch"`
+}
+
+// String implements fmt.Stringer
+func (p OsArchPair) String() string {
+	return p.Os + "/" + p.Arch
 }
 
 // PkgConfig is a package configuration
@@ -68,15 +80,37 @@ type PkgConfig struct {
 	OsMap   map[string]OsInfo `yaml:"os_map"`
 	ArchMap map[string]string `yaml:"arch_map"`
 	Ignore  []OsArchPair      `yaml:"ignore"`
+	// osLayers are the os_map entries for an OS with an architecture or C library, keyed like "linux-arm64-musl".
+	// They are kept undecoded so only the fields they give override the OS entry.
+	osLayers map[string]yaml.Node
+	// Depends lists packages that must be installed for this one, as 'pkg', 'pkg@version' or 'repo/pkg'
+	Depends []string `yaml:"depends"`
+	// PostInstall hooks run after a version is installed, and PreRemove hooks before it is removed
+	PostInstall []Hook `yaml:"post_install"`
+	PreRemove   []Hook `yaml:"pre_remove"`
+	// Env is environment variables set while a version is in use, like GOROOT: "[PKG_DIR]"
+	Env map[string]string `yaml:"env"`
+	// Completions maps a shell to the path of its completion file or directory in the package
+	Completions map[string]string `yaml:"completions"`
+	// Man is paths of man pages, or directories of them, in the package
+	Man SingleOrMulti `yaml:"man"`
+
+	// Repo is the name of the repo the recipe was found in
+	Repo string `yaml:"-"`
+	// AllowHooks is whether the repo the recipe was found in may run hooks
+	AllowHooks bool `yaml:"-"`
+	// RecipePath is the recipe file the configuration was parsed from
+	RecipePath string `yaml:"-"`
+	// releaseTags are the release tags versions were parsed from, keyed by version
+	releaseTags map[string]string
 }
 
 // InstallNotes combines package-level and OS-level installation notes
 func (pkgConf *PkgConfig) InstallNotes() string {
 	var installNotes string
 
-	pkgOS := GOOStoPkgOs[utils.GOOS]
 	note := pkgConf.InstallNote
-	osNote := pkgConf.OsMap[pkgOS].InstallNote
+	osNote := pkgConf.MyOsInfo().InstallNote
 	if note != "" || osNote != "" {
 		installNotes += color.BlueString("== %s\n", pkgConf.Title)
 	}
@@ -93,9 +127,8 @@ func (pkgConf *PkgConfig) InstallNotes() string {
 func (pkgConf *PkgConfig) RemoveNotes() string {
 	var removeNotes string
 
-	pkgOS := GOOStoPkgOs[utils.GOOS]
 	note := pkgConf.RemoveNote
-	osNote := pkgConf.OsMap[pkgOS].RemoveNote
+	osNote := pkgConf.MyOsInfo().RemoveNote
 	if note != "" || osNote != "" {
 		removeNotes += color.BlueString("== %s\n", pkgConf.Title)
 	}
@@ -138,38 +171,119 @@ func (sm *SingleOrMulti) UnmarshalYAML(unmarshal func(interface{}) error) error
 	return nil
 }
 
-// GetMyBinPaths gets all bin paths for this pakcage on this OS
-func (pkgConf *PkgConfig) GetMyBinPaths() ([]string, error) {
-	osStr, exists := GOOStoPkgOs[utils.GOOS]
+// osLayerKeys returns the os_map keys that override an OS entry on this platform, from least to most specific
+func osLayerKeys(pkgOs string) []string {
+	if utils.LIBC == "" {
+		return []string{pkgOs + "-" + utils.GOARCH}
+	}
+	return []string{
+		pkgOs + "-" + utils.LIBC,
+		pkgOs + "-" + utils.GOARCH,
+		pkgOs + "-" + utils.GOARCH + "-" + utils.LIBC,
+	}
+}
+
+// GetMyOsInfo gets the information for this OS, with the entries for this architecture and C library applied
+func (pkgConf *PkgConfig) GetMyOsInfo() (*OsInfo, error) {
+	pkgOs, exists := GOOStoPkgOs[utils.GOOS]
 	if !exists {
-		return []string{}, fmt.Errorf("unsupported OS")
+		return nil, fmt.Errorf("unsupported operating system")
 	}
-	osInfo, exists := pkgConf.OsMap[osStr]
+	osInfo, exists := pkgConf.OsMap[pkgOs]
 	if !exists {
-		return []string{}, fmt.Errorf("package does not support this OS")
+		return nil, fmt.Errorf("package has no binary for operating system: %s", pkgOs)
+	}
+	for _, key := range osLayerKeys(pkgOs) {
+		layer, exists := pkgConf.osLayers[key]
+		if !exists {
+			continue
+		}
+		// decoding merges into maps, so they are copied to leave the OS entry as it is
+		osInfo.Env = copyStringMap(osInfo.Env)
+		osInfo.Completions = copyStringMap(osInfo.Completions)
+		if err := layer.Decode(&osInfo); err != nil {
+			return nil, fmt.Errorf("invalid os_map entry %s: %v", key, err)
+		}
+	}
+	return &osInfo, nil
+}
+
+// MyOsInfo gets the information for this platform like GetMyOsInfo, or no information if the package doesn't support this OS
+func (pkgConf *PkgConfig) MyOsInfo() OsInfo {
+	osInfo, err := pkgConf.GetMyOsInfo()
+	if err != nil {
+		return OsInfo{}
+	}
+	return *osInfo
+}
+
+// HasLibcVariants determines whether the recipe has os_map entries for a C library
+func (pkgConf *PkgConfig) HasLibcVariants() bool {
+	for key := range pkgConf.osLayers {
+		for _, libc := range utils.Libcs {
+			if strings.HasSuffix(key, "-"+libc) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// copyStringMap copies a map, keeping nil maps nil
+func copyStringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(m))
+	for k, v := range m {
+		copied[k] = v
+	}
+	return copied
+}
+
+// GetMyBinPaths gets all bin paths for this pakcage on this OS
+func (pkgConf *PkgConfig) GetMyBinPaths() ([]string, error) {
+	osInfo, err := pkgConf.GetMyOsInfo()
+	if err != nil {
+		return []string{}, err
 	}
 	if osInfo.IsRawBinary {
 		return []string{pkgConf.Title}, nil
 	}
 	if len(osInfo.BinPaths.Values) == 0 {
-		osInfo.BinPaths = SingleOrMulti{[]string{""}}
+		return []string{""}, nil
 	}
 	return osInfo.BinPaths.Values, nil
 }
 
 // GetRenames gets all renames for this package on this OS
 func (pkgConf *PkgConfig) GetRenames() ([]RenameItem, error) {
-	osStr, exists := GOOStoPkgOs[utils.GOOS]
-	if !exists {
-		return []RenameItem{}, fmt.Errorf("unsupported OS")
-	}
-	osInfo, exists := pkgConf.OsMap[osStr]
-	if !exists {
-		return []RenameItem{}, fmt.Errorf("package does not support this OS")
+	osInfo, err := pkgConf.GetMyOsInfo()
+	if err != nil {
+		return []RenameItem{}, err
 	}
 	return osInfo.Renames, nil
 }
 
+// GetMyCompletions gets the completion paths for each shell on this OS, with OS-level paths overriding package-level ones
+func (pkgConf *PkgConfig) GetMyCompletions() map[string]string {
+	completions := make(map[string]string)
+	for _, paths := range []map[string]string{pkgConf.Completions, pkgConf.MyOsInfo().Completions} {
+		for shell, path := range paths {
+			completions[shell] = path
+		}
+	}
+	return completions
+}
+
+// GetMyManPaths gets the man page paths on this OS, with OS-level paths replacing package-level ones
+func (pkgConf *PkgConfig) GetMyManPaths() []string {
+	if osMan := pkgConf.MyOsInfo().Man.Values; len(osMan) != 0 {
+		return osMan
+	}
+	return pkgConf.Man.Values
+}
+
 // ParsePkgConfig parses an io.Reader as a package configuration
 func ParsePkgConfig(name string, r io.Reader) (*PkgConfig, error) {
 	dat, err := io.ReadAll(r)
@@ -182,28 +296,52 @@ func ParsePkgConfig(name string, r io.Reader) (*PkgConfig, error) {
 	}
 	pkgConf.Title = name
 
-	pkgConf.BaseDownloadUrl = strings.ReplaceAll(pkgConf.BaseDownloadUrl, "[GIT_USER]", pkgConf.GitUser)
-	pkgConf.BaseDownloadUrl = strings.ReplaceAll(pkgConf.BaseDownloadUrl, "[GIT_REPO]", pkgConf.GitRepo)
-
-	pkgConf.InfoUrl = strings.ReplaceAll(pkgConf.InfoUrl, "[GIT_USER]", pkgConf.GitUser)
-	pkgConf.InfoUrl = strings.ReplaceAll(pkgConf.InfoUrl, "[GIT_REPO]", pkgConf.GitRepo)
-
-	pkgConf.ReleasesUrl = strings.ReplaceAll(pkgConf.InfoUrl, "[GIT_USER]", pkgConf.GitUser)
-	pkgConf.ReleasesUrl = strings.ReplaceAll(pkgConf.InfoUrl, "[GIT_REPO]", pkgConf.GitRepo)
+	var layers struct {
+		OsMap map[string]yaml.Node `yaml:"os_map"`
+	}
+	if err = yaml.Unmarshal(dat, &layers); err != nil {
+		return nil, fmt.Errorf("unable to parse package recipe for %s: %v", name, err)
+	}
+	for key, layer := range layers.OsMap {
+		pkgOs, _, isLayer := strings.Cut(key, "-")
+		if !isLayer {
+			continue
+		}
+		if _, exists := pkgConf.OsMap[pkgOs]; !exists {
+			return nil, fmt.Errorf("unable to parse package recipe for %s: os_map entry %s needs an entry for %s", name, key, pkgOs)
+		}
+		var osInfo OsInfo
+		if err = layer.Decode(&osInfo); err != nil {
+			return nil, fmt.Errorf("unable to parse package recipe for %s: os_map entry %s: %v", name, key, err)
+		}
+		if pkgConf.osLayers == nil {
+			pkgConf.osLayers = make(map[string]yaml.Node)
+		}
+		pkgConf.osLayers[key] = layer
+		delete(pkgConf.OsMap, key)
+	}
 
-	pkgConf.SourceUrl = strings.ReplaceAll(pkgConf.SourceUrl, "[GIT_USER]", pkgConf.GitUser)
-	pkgConf.SourceUrl = strings.ReplaceAll(pkgConf.SourceUrl, "[GIT_REPO]", pkgConf.GitRepo)
+	// version variables are left for GetAssetStemExtUrl
+	repoVars := pkgConf.repoVars()
+	for _, field := range []*string{
+		&pkgConf.InfoUrl,
+		&pkgConf.ReleasesUrl,
+		&pkgConf.SourceUrl,
+		&pkgConf.BaseDownloadUrl,
+	} {
+		*field = repoVars.expand(*field)
+	}
 
 	pkgConf.GiteaURL = strings.TrimRight(pkgConf.GiteaURL, "/")
 
 	return &pkgConf, nil
 }
 
-// ParsePkgConfigLocal checks all known repos for a given package
-func ParsePkgConfigLocal(pkgRepos []*config.PkgRepo, pkg string) (*PkgConfig, error) {
-	var pkgConfPath string
-	for _, pkgRepo := range pkgRepos {
-		pkgPath := filepath.Join(pkgRepo.Path(), "pkgs", pkg+utils.PkgRecipeExt)
+// FindPkgRepos returns all known repos that have a recipe for a given package, in priority order
+func FindPkgRepos(pkgRepos []*config.PkgRepo, pkg string) ([]*config.PkgRepo, error) {
+	var found []*config.PkgRepo
+	for _, pkgRepo := range config.ByPriority(pkgRepos) {
+		pkgPath := filepath.Join(pkgRepo.PackagePath(), pkg+utils.PkgRecipeExt)
 		_, err := os.Stat(pkgPath)
 		if err != nil {
 			if !errors.Is(err, fs.ErrNotExist) {
@@ -211,20 +349,95 @@ func ParsePkgConfigLocal(pkgRepos []*config.PkgRepo, pkg string) (*PkgConfig, er
 			}
 			continue
 		}
-		pkgConfPath = pkgPath
-		break
+		found = append(found, pkgRepo)
 	}
-	if pkgConfPath == "" {
-		return nil, fmt.Errorf("no package recipe exists for %s", pkg)
+	return found, nil
+}
+
+// ParsePkgConfigLocal checks all known repos for a given package, using the highest priority recipe
+func ParsePkgConfigLocal(pkgRepos []*config.PkgRepo, pkg string) (*PkgConfig, error) {
+	found, err := FindPkgRepos(pkgRepos, pkg)
+	if err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		return nil, ui.Errorf(ui.CodeRecipeNotFound, "no package recipe exists for %s", pkg)
 	}
 
-	fi, err := os.Open(pkgConfPath)
+	recipePath := filepath.Join(found[0].PackagePath(), pkg+utils.PkgRecipeExt)
+	fi, err := os.Open(recipePath)
 	if err != nil {
 		return nil, err
 	}
 	defer fi.Close()
 
-	return ParsePkgConfig(pkg, fi)
+	pkgConf, err := ParsePkgConfig(pkg, fi)
+	if err != nil {
+		return nil, err
+	}
+	pkgConf.Repo = found[0].Name
+	pkgConf.AllowHooks = found[0].AllowHooks
+	pkgConf.RecipePath = recipePath
+	return pkgConf, nil
+}
+
+// ParseInstalledPkgConfig parses the recipe for an installed package version from the repo it was installed from,
+// or the copy kept when it was installed if the recipe is gone from that repo.
+// Versions installed before their repo was recorded use the first repo with a recipe of the same name.
+func ParseInstalledPkgConfig(pkgRepos []*config.PkgRepo, pkg string, pkgVerStem string) (*PkgConfig, error) {
+	info, err := CheckInstalled(pkg, pkgVerStem)
+	if err != nil {
+		return nil, err
+	}
+	if info != nil {
+		if repos, err := config.SelectRepos(pkgRepos, info.Repo); err == nil {
+			pkgConf, err := ParsePkgConfigLocal(repos, pkg)
+			if ui.CodeOf(err) != ui.CodeRecipeNotFound {
+				return pkgConf, err
+			}
+		}
+		// another repo's recipe with the same name may be for a different package,
+		// so once the recipe is gone from its repo only the saved copy describes this version
+		saved, err := ParseSavedPkgConfig(pkg, pkgVerStem)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil, ui.Errorf(ui.CodeRecipeNotFound, "recipe for %s is no longer in repository %q and no copy was kept", pkgVerStem, info.Repo)
+			}
+			return nil, err
+		}
+		saved.Repo = info.Repo
+		return saved, nil
+	}
+	// versions installed before their repo was recorded can only be matched by name
+	pkgConf, err := ParsePkgConfigLocal(pkgRepos, pkg)
+	if ui.CodeOf(err) != ui.CodeRecipeNotFound {
+		return pkgConf, err
+	}
+	saved, savedErr := ParseSavedPkgConfig(pkg, pkgVerStem)
+	if savedErr != nil {
+		if errors.Is(savedErr, fs.ErrNotExist) {
+			return nil, err
+		}
+		return nil, savedErr
+	}
+	return saved, nil
+}
+
+// ParseActivePkgConfig parses the recipe for the version of an installed package in use,
+// or for its newest installed version if none is in use
+func ParseActivePkgConfig(pkgRepos []*config.PkgRepo, pkg string) (*PkgConfig, error) {
+	stems, err := utils.InstalledPkgVerStems(pkg)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if len(stems) == 0 {
+		return nil, ui.Errorf(ui.CodeNotInstalled, "%s is not installed", pkg)
+	}
+	recipeStem := stems[len(stems)-1]
+	if using, err := CheckUsing(pkg); err == nil && using != nil {
+		recipeStem = *using
+	}
+	return ParseInstalledPkgConfig(pkgRepos, pkg, recipeStem)
 }
 
 // GetLatestVersion uses the configuration's latest-strategy to determine the latest version of the package
@@ -258,9 +471,107 @@ func (pkgConf *PkgConfig) GetLatestVersion() (*string, error) {
 	if err != nil {
 		return nil, fmt.Errorf("unable to parse version: %v", err)
 	}
+	pkgConf.recordTag(*parsedVer, version)
 	return parsedVer, nil
 }
 
+// GetVersions returns the released versions of the package, newest first.
+// For strategies without a release list, only the latest version is returned.
+func (pkgConf *PkgConfig) GetVersions() ([]string, error) {
+	return pkgConf.listVersions(nil)
+}
+
+// listVersions returns the released versions of the package like GetVersions,
+// fetching further pages of releases until done returns true for the versions so far, or all pages if done is nil
+func (pkgConf *PkgConfig) listVersions(done func([]string) bool) ([]string, error) {
+	var versions []string
+	// versions are parsed as pages arrive, so done can stop the listing
+	parsed := 0
+	releasesDone := func(releases []ReleaseTagInfo) bool {
+		for _, release := range releases[parsed:] {
+			if release.Draft || (release.Prerelease && !pkgConf.AllowPrerelease) {
+				continue
+			}
+			// releases that don't match the version format aren't installable, so they are skipped
+			if ver, err := ParseVersion(release.TagName, pkgConf.VersionFormat); err == nil {
+				versions = append(versions, *ver)
+				pkgConf.recordTag(*ver, release.TagName)
+			}
+		}
+		parsed = len(releases)
+		return done != nil && done(versions)
+	}
+	var err error
+	switch pkgConf.LatestStrategy {
+	case "github-release":
+		_, err = getGithubReleaseTags(pkgConf.GitUser, pkgConf.GitRepo, releasesDone)
+	case "gitea-release":
+		_, err = getGiteaReleaseTags(pkgConf.GiteaURL, pkgConf.GitUser, pkgConf.GitRepo, releasesDone)
+	default:
+		latest, err := pkgConf.GetLatestVersion()
+		if err != nil {
+			return nil, err
+		}
+		return []string{*latest}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// recordTag remembers the release tag a version was parsed from
+func (pkgConf *PkgConfig) recordTag(ver string, tag string) {
+	if pkgConf.releaseTags == nil {
+		pkgConf.releaseTags = make(map[string]string)
+	}
+	pkgConf.releaseTags[ver] = tag
+}
+
+// ReleaseTag returns the release tag a version was parsed from,
+// looking up the released versions if the version wasn't resolved from them already
+func (pkgConf *PkgConfig) ReleaseTag(ver string) (string, error) {
+	if tag, ok := pkgConf.releaseTags[ver]; ok {
+		return tag, nil
+	}
+	tagListed := func([]string) bool {
+		_, ok := pkgConf.releaseTags[ver]
+		return ok
+	}
+	if _, err := pkgConf.listVersions(tagListed); err != nil {
+		return "", fmt.Errorf("unable to find release tag for %s: %v", ver, err)
+	}
+	if tag, ok := pkgConf.releaseTags[ver]; ok {
+		return tag, nil
+	}
+	return "", fmt.Errorf("no release of %s has version %s", pkgConf.Title, ver)
+}
+
+// GetMatchingVersion returns the highest released version matching a constraint like 1.22.x.
+// Releases are listed newest first, so the listing stops at the first page with a matching version.
+func (pkgConf *PkgConfig) GetMatchingVersion(constraint string) (*string, error) {
+	var match *string
+	matchListed := func(versions []string) bool {
+		for _, ver := range versions {
+			if utils.MatchVersion(constraint, ver) && (match == nil || utils.CompareVersions(ver, *match) > 0) {
+				m := ver
+				match = &m
+			}
+		}
+		return match != nil
+	}
+	versions, err := pkgConf.listVersions(matchListed)
+	if err != nil {
+		return nil, err
+	}
+	// strategies without a release list return without calling done
+	matchListed(versions)
+	if match == nil {
+		return nil, fmt.Errorf("no released version of %s matches %s", pkgConf.Title, constraint)
+	}
+	return match, nil
+}
+
 func ParseVersion(versionStr string, versionFmt string) (*string, error) {
 	if versionFmt == "" {
 		versionFmt = "[VER]"
@@ -277,33 +588,42 @@ func ParseVersion(versionStr string, versionFmt string) (*string, error) {
 	return &matchedVer[1], nil
 }
 
+// GetAssetStemExtUrl gets the file stem, extension and download URL of the asset for a version on this OS and architecture
 func (pkgConf *PkgConfig) GetAssetStemExtUrl(version string) (*string, *string, *string, error) {
-	pkgOs, exists := GOOStoPkgOs[utils.GOOS]
-	if !exists {
-		return nil, nil, nil, fmt.Errorf("unsupported operating system")
-	}
-	osInf, exists := pkgConf.OsMap[pkgOs]
-	if !exists {
-		return nil, nil, nil, fmt.Errorf("package has no binary for operating system: %s", pkgOs)
+	osInf, err := pkgConf.GetMyOsInfo()
+	if err != nil {
+		return nil, nil, nil, err
 	}
 	archStr, exists := pkgConf.ArchMap[utils.GOARCH]
 	if !exists {
 		return nil, nil, nil, fmt.Errorf("package has no binary for architecture: %s", utils.GOARCH)
 	}
-	baseUrl := pkgConf.BaseDownloadUrl
-	baseUrl = strings.ReplaceAll(baseUrl, "[VER]", version)
-	baseUrl = strings.ReplaceAll(baseUrl, "[OS]", osInf.Name)
-	baseUrl = strings.ReplaceAll(baseUrl, "[ARCH]", archStr)
-	baseUrl = strings.ReplaceAll(baseUrl, "[EXT]", osInf.Ext)
-
-	fileStem := pkgConf.FilenameFormat
+	fileFormat := pkgConf.FilenameFormat
 	if osInf.FilenameFormatOverride != "" {
-		fileStem = osInf.FilenameFormatOverride
+		fileFormat = osInf.FilenameFormatOverride
+	}
+	// the extension is appended to the URL below, so it is left out of the stem
+	fileFormat = strings.ReplaceAll(fileFormat, ".[EXT]", "")
+	// the tag is only looked up when needed, since that can mean fetching the releases
+	if strings.Contains(pkgConf.BaseDownloadUrl+fileFormat, "[TAG]") {
+		if _, err := pkgConf.ReleaseTag(version); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	vars := pkgConf.versionVars(version).with(templateVars{
+		"OS":   osInf.Name,
+		"ARCH": archStr,
+		"EXT":  osInf.Ext,
+	})
+	baseUrl, err := vars.expandStrict(pkgConf.BaseDownloadUrl)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid base_download_url: %v", err)
+	}
+
+	fileStem, err := vars.expandStrict(fileFormat)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid filename format: %v", err)
 	}
-	fileStem = strings.ReplaceAll(fileStem, "[VER]", version)
-	fileStem = strings.ReplaceAll(fileStem, "[OS]", osInf.Name)
-	fileStem = strings.ReplaceAll(fileStem, "[ARCH]", archStr)
-	fileStem = strings.ReplaceAll(fileStem, ".[EXT]", "")
 	dot := ""
 	if osInf.Ext != "" {
 		dot = "."
@@ -328,3 +648,37 @@ func ParsePkgConfigPath(repoPath, pkg string) (*PkgConfig, error) {
 
 	return pkgConfig, nil
 }
+
+// SupportedPlatforms lists the OS and architecture pairs the package has binaries for,
+// using webman OS names and Go architecture names
+func (pkgConf *PkgConfig) SupportedPlatforms() []OsArchPair {
+	var pairs []OsArchPair
+	for pkgOs := range pkgConf.OsMap {
+	archLoop:
+		for arch := range pkgConf.ArchMap {
+			for _, ignorePair := range pkgConf.Ignore {
+				if ignorePair.Os == pkgOs && ignorePair.Arch == arch {
+					continue archLoop
+				}
+			}
+			pairs = append(pairs, OsArchPair{Os: pkgOs, Arch: arch})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].Os != pairs[j].Os {
+			return pairs[i].Os < pairs[j].Os
+		}
+		return pairs[i].Arch < pairs[j].Arch
+	})
+	return pairs
+}
+
+// SupportsPlatform determines whether the package has binaries for the given webman OS name and Go architecture
+func (pkgConf *PkgConfig) SupportsPlatform(pkgOs string, arch string) bool {
+	for _, pair := range pkgConf.SupportedPlatforms() {
+		if pair.Os == pkgOs && pair.Arch == arch {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package pkgparse
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/candrewlee14/webman/utils"
+
+	"github.com/matryer/is"
+)
+
+var layeredRecipe = `tagline: A test package
+about: Used for testing os_map entries for architectures and C libraries
+base_download_url: https://example.com/[VER]/
+filename_format: foo-[ARCH]-[OS]
+latest_strategy: github-release
+git_user: example
+git_repo: foo
+os_map:
+  linux:
+    name: unknown-linux-gnu
+    ext: tar.gz
+    env:
+      FOO_HOME: "[PKG_DIR]"
+  linux-musl:
+    name: unknown-linux-musl
+  linux-arm64:
+    extract_has_root: true
+    env:
+      FOO_ARCH: arm64
+  linux-arm64-musl:
+    ext: zip
+arch_map:
+  amd64: x86_64
+  arm64: aarch64
+`
+
+func TestGetMyOsInfo(t *testing.T) {
+	assert := is.New(t)
+
+	pkgConf, err := ParsePkgConfig("foo", strings.NewReader(layeredRecipe))
+	assert.NoErr(err)                                  // Should parse recipe
+	assert.Equal(len(pkgConf.OsMap), 1)                // Layers should not be OSs
+	assert.True(pkgConf.HasLibcVariants())             // Should find C library entries
+	assert.Equal(len(pkgConf.SupportedPlatforms()), 2) // Layers should not add platforms
+	defer func(goos, goarch, libc string) { utils.GOOS, utils.GOARCH, utils.LIBC = goos, goarch, libc }(utils.GOOS, utils.GOARCH, utils.LIBC)
+
+	utils.GOOS, utils.GOARCH, utils.LIBC = "linux", "amd64", "gnu"
+	osInfo, err := pkgConf.GetMyOsInfo()
+	assert.NoErr(err)                              // Should get OS info
+	assert.Equal(osInfo.Name, "unknown-linux-gnu") // Should use OS entry without matching layers
+	assert.True(!osInfo.ExtractHasRoot)            // Should not use other architecture
+
+	utils.LIBC = "musl"
+	osInfo, err = pkgConf.GetMyOsInfo()
+	assert.NoErr(err)                               // Should get OS info
+	assert.Equal(osInfo.Name, "unknown-linux-musl") // Should use C library entry
+	assert.Equal(osInfo.Ext, "tar.gz")              // Should keep fields the layer doesn't give
+
+	utils.GOARCH = "arm64"
+	osInfo, err = pkgConf.GetMyOsInfo()
+	assert.NoErr(err)                                                                         // Should get OS info
+	assert.Equal(osInfo.Name, "unknown-linux-musl")                                           // Should apply C library entry
+	assert.True(osInfo.ExtractHasRoot)                                                        // Should apply architecture entry
+	assert.Equal(osInfo.Ext, "zip")                                                           // Most specific entry should win
+	assert.Equal(osInfo.Env, map[string]string{"FOO_HOME": "[PKG_DIR]", "FOO_ARCH": "arm64"}) // Maps should be merged
+	assert.Equal(len(pkgConf.OsMap["linux"].Env), 1)                                          // OS entry should be left as it is
+
+	_, err = ParsePkgConfig("foo", strings.NewReader(strings.Replace(layeredRecipe, "  linux:\n", "  macos:\n", 1)))
+	assert.True(err != nil) // Layers should need an OS entry
+}
@@ -0,0 +1,71 @@
+package pkgparse
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/candrewlee14/webman/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectConfig is a project file (.webman.yml) selecting package versions for a directory tree
+type ProjectConfig struct {
+	// Packages are the versions to use, as 'pkg' or 'pkg@version', where the version may be a constraint like 1.22.x
+	Packages []string `yaml:"packages"`
+}
+
+// FindProjectFile returns the path of the nearest project file in dir or its parents,
+// or an empty string if there is none
+func FindProjectFile(dir string) (string, error) {
+	for {
+		path := filepath.Join(dir, utils.ProjectFileName)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// ParseProjectFile parses a project file
+func ParseProjectFile(path string) (*ProjectConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var project ProjectConfig
+	if err := yaml.Unmarshal(data, &project); err != nil {
+		return nil, fmt.Errorf("unable to parse project file %s: %v", path, err)
+	}
+	for _, arg := range project.Packages {
+		if _, _, _, err := utils.ParsePkgVer(arg); err != nil {
+			return nil, fmt.Errorf("invalid package %q in project file %s: %v", arg, path, err)
+		}
+	}
+	return &project, nil
+}
+
+// NewestInstalledVersion returns the newest installed version stem of a package satisfying the requested version,
+// or an empty string if none is installed
+func NewestInstalledVersion(pkg string, ver string) string {
+	stems, err := utils.InstalledPkgVerStems(pkg)
+	if err != nil {
+		return ""
+	}
+	for i := len(stems) - 1; i >= 0; i-- {
+		_, installedVer := utils.ParseStem(stems[i])
+		if ver == "" || utils.SatisfiesVersion(ver, installedVer) {
+			return stems[i]
+		}
+	}
+	return ""
+}
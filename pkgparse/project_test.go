@@ -0,0 +1,42 @@
+package pkgparse
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/candrewlee14/webman/utils"
+
+	"github.com/matryer/is"
+)
+
+func TestProjectFile(t *testing.T) {
+	assert := is.New(t)
+
+	tmp := t.TempDir()
+	utils.Init(tmp)
+	project := filepath.Join(tmp, "project")
+	sub := filepath.Join(project, "src", "cmd")
+	assert.NoErr(os.MkdirAll(sub, os.ModePerm)) // Should create project dirs
+	projectPath := filepath.Join(project, utils.ProjectFileName)
+	assert.NoErr(os.WriteFile(projectPath, []byte("packages:\n  - go@1.22.x\n  - rg\n"), os.ModePerm)) // Should write project file
+
+	found, err := FindProjectFile(sub)
+	assert.NoErr(err)                // Should search parents
+	assert.Equal(found, projectPath) // Nearest project file should be found
+	found, err = FindProjectFile(tmp)
+	assert.NoErr(err)       // Should search up to the root
+	assert.Equal(found, "") // Directories outside the project have no project file
+
+	conf, err := ParseProjectFile(projectPath)
+	assert.NoErr(err)                                        // Should parse project file
+	assert.Equal(conf.Packages, []string{"go@1.22.x", "rg"}) // Packages should be listed
+
+	for _, stem := range []string{"go-1.21.0", "go-1.22.1", "go-1.22.3"} {
+		assert.NoErr(os.MkdirAll(filepath.Join(utils.WebmanPkgDir, "go", stem), os.ModePerm)) // Should create version dir
+	}
+	assert.Equal(NewestInstalledVersion("go", "1.22.x"), "go-1.22.3") // Newest matching version should be used
+	assert.Equal(NewestInstalledVersion("go", "1.21.0"), "go-1.21.0") // Exact version should be used
+	assert.Equal(NewestInstalledVersion("go", "1.20.x"), "")          // Missing version should not match
+	assert.Equal(NewestInstalledVersion("rg", ""), "")                // Missing package should not match
+}
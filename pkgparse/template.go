@@ -0,0 +1,139 @@
+package pkgparse
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/candrewlee14/webman/utils"
+)
+
+// templateVarExp matches a template variable like [VER] or [GIT_USER]
+var templateVarExp = regexp.MustCompile(`\[([A-Z][A-Z0-9_]*)\]`)
+
+// versionPartsExp matches the numeric major, minor and patch parts at the start of a version
+var versionPartsExp = regexp.MustCompile(`^(\d+)(?:\.(\d+))?(?:\.(\d+))?`)
+
+// knownVars are the names of all template variables, some of which only have values in some fields or for some versions
+var knownVars = map[string]bool{
+	"VER": true, "VER_NUM": true, "MAJOR": true, "MINOR": true, "PATCH": true, "TAG": true,
+	"OS": true, "ARCH": true, "EXT": true, "GIT_USER": true, "GIT_REPO": true,
+	"PKG_DIR": true, "HOME": true, "WEBMAN_DIR": true, "WEBMAN_BIN": true,
+}
+
+// templateVars are the values of the variables in a recipe template, keyed by name without brackets
+type templateVars map[string]string
+
+// expand replaces the known variables in a template, leaving unknown ones as they are
+func (vars templateVars) expand(s string) string {
+	return templateVarExp.ReplaceAllStringFunc(s, func(match string) string {
+		if value, ok := vars[match[1:len(match)-1]]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+// expandStrict replaces the variables in a template, failing on the first variable without a value
+func (vars templateVars) expandStrict(s string) (string, error) {
+	for _, match := range templateVarExp.FindAllStringSubmatch(s, -1) {
+		if _, ok := vars[match[1]]; ok {
+			continue
+		}
+		if knownVars[match[1]] {
+			return "", fmt.Errorf("variable %s has no value here in %q", match[0], s)
+		}
+		return "", fmt.Errorf("unknown variable %s in %q", match[0], s)
+	}
+	return vars.expand(s), nil
+}
+
+// with returns a copy of the variables with more added
+func (vars templateVars) with(more templateVars) templateVars {
+	all := make(templateVars, len(vars)+len(more))
+	for name, value := range vars {
+		all[name] = value
+	}
+	for name, value := range more {
+		all[name] = value
+	}
+	return all
+}
+
+// repoVars returns the variables for the git repository of the package, [GIT_USER] and [GIT_REPO]
+func (pkgConf *PkgConfig) repoVars() templateVars {
+	return templateVars{
+		"GIT_USER": pkgConf.GitUser,
+		"GIT_REPO": pkgConf.GitRepo,
+	}
+}
+
+// versionVars returns the variables for a version of the package:
+// [VER], [VER_NUM] without a leading "v", the [MAJOR], [MINOR] and [PATCH] parts the version has,
+// and [TAG], the release tag the version was parsed from, if it is known
+func (pkgConf *PkgConfig) versionVars(ver string) templateVars {
+	verNum := strings.TrimPrefix(ver, "v")
+	vars := pkgConf.repoVars().with(templateVars{
+		"VER":     ver,
+		"VER_NUM": verNum,
+	})
+	if match := versionPartsExp.FindStringSubmatch(verNum); match != nil {
+		for i, name := range []string{"MAJOR", "MINOR", "PATCH"} {
+			if match[i+1] != "" {
+				vars[name] = match[i+1]
+			}
+		}
+	}
+	if tag, ok := pkgConf.releaseTags[ver]; ok {
+		vars["TAG"] = tag
+	}
+	return vars
+}
+
+// pkgVars returns the variables for an installed package version, used in environment variables and hooks:
+// the version variables, [PKG_DIR], [HOME], [WEBMAN_DIR] and [WEBMAN_BIN]
+func (pkgConf *PkgConfig) pkgVars(pkgDir string, ver string) templateVars {
+	home, _ := os.UserHomeDir()
+	return pkgConf.versionVars(ver).with(templateVars{
+		"PKG_DIR":    pkgDir,
+		"HOME":       home,
+		"WEBMAN_DIR": utils.WebmanDir,
+		"WEBMAN_BIN": utils.WebmanBinDir,
+	})
+}
+
+// CheckTemplates checks that the URL fields of the recipe only use known variables
+func (pkgConf *PkgConfig) CheckTemplates() error {
+	urlFields := map[string]string{
+		"info_url":     pkgConf.InfoUrl,
+		"releases_url": pkgConf.ReleasesUrl,
+		"source_url":   pkgConf.SourceUrl,
+	}
+	for field, value := range urlFields {
+		if _, err := pkgConf.repoVars().expandStrict(value); err != nil {
+			return fmt.Errorf("%s: %v", field, err)
+		}
+	}
+	assetVars := pkgConf.versionVars("1.0.0").with(templateVars{"TAG": "", "OS": "", "ARCH": "", "EXT": ""})
+	assetFields := map[string]string{
+		"base_download_url": pkgConf.BaseDownloadUrl,
+		"filename_format":   pkgConf.FilenameFormat,
+	}
+	for pkgOs, osInfo := range pkgConf.OsMap {
+		assetFields["os_map."+pkgOs+".filename_format_override"] = osInfo.FilenameFormatOverride
+	}
+	for key, layer := range pkgConf.osLayers {
+		var osInfo OsInfo
+		if err := layer.Decode(&osInfo); err != nil {
+			return fmt.Errorf("os_map.%s: %v", key, err)
+		}
+		assetFields["os_map."+key+".filename_format_override"] = osInfo.FilenameFormatOverride
+	}
+	for field, value := range assetFields {
+		if _, err := assetVars.expandStrict(value); err != nil {
+			return fmt.Errorf("%s: %v", field, err)
+		}
+	}
+	return nil
+}
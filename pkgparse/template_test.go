@@ -0,0 +1,82 @@
+package pkgparse
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/candrewlee14/webman/utils"
+
+	"github.com/matryer/is"
+)
+
+var templateRecipe = `tagline: A test package
+about: Used for testing recipe templates
+info_url: https://github.com/[GIT_USER]/[GIT_REPO]
+releases_url: https://github.com/[GIT_USER]/[GIT_REPO]/releases
+base_download_url: https://github.com/[GIT_USER]/[GIT_REPO]/releases/download/[TAG]/
+filename_format: foo-[VER_NUM]-[MAJOR].[MINOR].[PATCH]-[OS]-[ARCH].[EXT]
+version_format: ^v[VER]$
+latest_strategy: github-release
+git_user: example
+git_repo: foo
+os_map:
+  linux:
+    name: linux
+    ext: tar.gz
+    bin_path: bin
+  linux-arm64:
+    ext: tar.xz
+    bin_path: [aarch64/bin]
+    renames:
+      - from: foo-aarch64
+        to: foo
+arch_map:
+  amd64: x86_64
+  arm64: aarch64
+`
+
+func TestTemplates(t *testing.T) {
+	assert := is.New(t)
+
+	vars := templateVars{"VER": "1.2.3"}
+	assert.Equal(vars.expand("foo-[VER]-[OTHER]"), "foo-1.2.3-[OTHER]") // Unknown variables should be left as they are
+	_, err := vars.expandStrict("foo-[VER]-[OTHER]")
+	assert.True(err != nil) // Unknown variables should be an error when strict
+
+	pkgConf, err := ParsePkgConfig("foo", strings.NewReader(templateRecipe))
+	assert.NoErr(err)                                                            // Should parse recipe
+	assert.Equal(pkgConf.InfoUrl, "https://github.com/example/foo")              // Should expand info URL
+	assert.Equal(pkgConf.ReleasesUrl, "https://github.com/example/foo/releases") // Should expand releases URL on its own
+	assert.NoErr(pkgConf.CheckTemplates())                                       // Should only use known variables
+	_, isOs := pkgConf.OsMap["linux-arm64"]
+	assert.True(!isOs) // Override should not be an OS
+
+	utils.GOOS, utils.GOARCH = "linux", "amd64"
+	defer utils.Init(t.TempDir())
+	pkgConf.recordTag("1.2.3", "v1.2.3")
+	stem, ext, url, err := pkgConf.GetAssetStemExtUrl("1.2.3")
+	assert.NoErr(err)                                                                                                 // Should expand asset
+	assert.Equal(*stem, "foo-1.2.3-1.2.3-linux-x86_64")                                                               // Should fill in version parts
+	assert.Equal(*ext, "tar.gz")                                                                                      // Should use OS extension
+	assert.Equal(*url, "https://github.com/example/foo/releases/download/v1.2.3/foo-1.2.3-1.2.3-linux-x86_64.tar.gz") // Should use the raw tag, not the version format
+
+	pkgConf.recordTag("1.2", "v1.2")
+	_, _, _, err = pkgConf.GetAssetStemExtUrl("1.2")
+	assert.True(err != nil) // Version without a patch part should not make one up
+
+	utils.GOARCH = "arm64"
+	_, ext, _, err = pkgConf.GetAssetStemExtUrl("1.2.3")
+	assert.NoErr(err)            // Should expand asset
+	assert.Equal(*ext, "tar.xz") // Should use architecture extension
+	binPaths, err := pkgConf.GetMyBinPaths()
+	assert.NoErr(err)                               // Should get bin paths
+	assert.Equal(binPaths, []string{"aarch64/bin"}) // Should use architecture bin paths
+	renames, err := pkgConf.GetRenames()
+	assert.NoErr(err)                            // Should get renames
+	assert.Equal(renames[0].From, "foo-aarch64") // Should use architecture renames
+
+	pkgConf.FilenameFormat = "foo-[VERSION]"
+	_, _, _, err = pkgConf.GetAssetStemExtUrl("1.2.3")
+	assert.True(err != nil)                      // Unknown variable should fail the download URL
+	assert.True(pkgConf.CheckTemplates() != nil) // Unknown variable should fail the check
+}
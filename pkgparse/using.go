@@ -1,6 +1,8 @@
 package pkgparse
 
 import (
+	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
 
@@ -12,13 +14,45 @@ import (
 // UsingInfo is which version of a package is being used
 type UsingInfo struct {
 	Using string `yaml:"using"`
+	// Env is the environment variables the version in use sets
+	Env map[string]string `yaml:"env,omitempty"`
+}
+
+func usingPath(pkg string) string {
+	return filepath.Join(utils.WebmanPkgDir, pkg, utils.UsingFileName)
+}
+
+// readUsing reads the using file of a package, returning nil if it doesn't exist
+func readUsing(pkg string) (*UsingInfo, error) {
+	usingContent, err := os.ReadFile(usingPath(pkg))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var usingInfo UsingInfo
+	if err = yaml.Unmarshal(usingContent, &usingInfo); err != nil {
+		return nil, err
+	}
+	return &usingInfo, nil
+}
+
+func writeUsing(pkg string, usingInfo *UsingInfo) error {
+	data, err := yaml.Marshal(usingInfo)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(usingPath(pkg), data, os.ModePerm); err != nil {
+		return err
+	}
+	return nil
 }
 
 // Check using file.
 // If UsingFile doesn't exist, it is not using anything
 func CheckUsing(pkg string) (*string, error) {
-	usingPath := filepath.Join(utils.WebmanPkgDir, pkg, utils.UsingFileName)
-	usingContent, err := os.ReadFile(usingPath)
+	usingContent, err := os.ReadFile(usingPath(pkg))
 	if err != nil {
 		return nil, nil
 	}
@@ -30,23 +64,24 @@ func CheckUsing(pkg string) (*string, error) {
 }
 
 func WriteUsing(pkg string, using string) error {
-	usingInfo := UsingInfo{
-		Using: using,
-	}
-	data, err := yaml.Marshal(usingInfo)
+	return writeUsing(pkg, &UsingInfo{Using: using})
+}
+
+// WriteUsingEnv records the environment variables of the package version in use
+func (pkgConf *PkgConfig) WriteUsingEnv(pkgVerStem string) error {
+	usingInfo, err := readUsing(pkgConf.Title)
 	if err != nil {
 		return err
 	}
-	usingPath := filepath.Join(utils.WebmanPkgDir, pkg, utils.UsingFileName)
-	if err := os.WriteFile(usingPath, data, os.ModePerm); err != nil {
-		return err
+	if usingInfo == nil {
+		usingInfo = &UsingInfo{Using: pkgVerStem}
 	}
-	return nil
+	usingInfo.Env = pkgConf.EnvVars(pkgVerStem)
+	return writeUsing(pkgConf.Title, usingInfo)
 }
 
 func RemoveUsing(pkg string) error {
-	usingPath := filepath.Join(utils.WebmanPkgDir, pkg, utils.UsingFileName)
-	if err := os.Remove(usingPath); err != nil {
+	if err := os.Remove(usingPath(pkg)); err != nil {
 		return err
 	}
 	return nil
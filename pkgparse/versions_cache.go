@@ -0,0 +1,54 @@
+package pkgparse
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/candrewlee14/webman/utils"
+)
+
+// VersionsCacheTTL is how long cached release versions are used before they are fetched again
+const VersionsCacheTTL = time.Hour
+
+// versionsCache is the cached list of released versions of a package
+type versionsCache struct {
+	Repo     string    `json:"repo"`
+	Fetched  time.Time `json:"fetched"`
+	Versions []string  `json:"versions"`
+}
+
+func versionsCachePath(pkg string) string {
+	return filepath.Join(utils.WebmanCacheDir, "versions", pkg+".json")
+}
+
+// GetCachedVersions returns the recent released versions of the package, newest first,
+// reusing the versions fetched in the last VersionsCacheTTL.
+// Only the first page of releases with an installable version is fetched.
+func (pkgConf *PkgConfig) GetCachedVersions() ([]string, error) {
+	cachePath := versionsCachePath(pkgConf.Title)
+	if data, err := os.ReadFile(cachePath); err == nil {
+		var cache versionsCache
+		// an unreadable cache is just fetched again
+		if err := json.Unmarshal(data, &cache); err == nil &&
+			cache.Repo == pkgConf.Repo && time.Since(cache.Fetched) < VersionsCacheTTL {
+			return cache.Versions, nil
+		}
+	}
+	versions, err := pkgConf.listVersions(func(versions []string) bool { return len(versions) != 0 })
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(versionsCache{Repo: pkgConf.Repo, Fetched: time.Now(), Versions: versions})
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(cachePath), os.ModePerm); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(cachePath, data, 0o644); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
@@ -0,0 +1,40 @@
+package pkgparse
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/candrewlee14/webman/utils"
+
+	"github.com/matryer/is"
+)
+
+func TestGetCachedVersions(t *testing.T) {
+	assert := is.New(t)
+
+	utils.Init(t.TempDir())
+	writeCache := func(cache versionsCache) {
+		data, err := json.Marshal(cache)
+		assert.NoErr(err)                                                              // Should marshal cache
+		assert.NoErr(os.MkdirAll(filepath.Dir(versionsCachePath("foo")), os.ModePerm)) // Should create cache dir
+		assert.NoErr(os.WriteFile(versionsCachePath("foo"), data, os.ModePerm))        // Should write cache
+	}
+	// without a latest strategy, fetching versions fails, so a result must come from the cache
+	pkgConf := &PkgConfig{Title: "foo", Repo: "webman"}
+
+	writeCache(versionsCache{Repo: "webman", Fetched: time.Now(), Versions: []string{"1.1.0", "1.0.0"}})
+	versions, err := pkgConf.GetCachedVersions()
+	assert.NoErr(err)                                  // Fresh cache should be used
+	assert.Equal(versions, []string{"1.1.0", "1.0.0"}) // Cached versions should be returned
+
+	writeCache(versionsCache{Repo: "webman", Fetched: time.Now().Add(-2 * VersionsCacheTTL), Versions: []string{"1.0.0"}})
+	_, err = pkgConf.GetCachedVersions()
+	assert.True(err != nil) // Expired cache should be fetched again
+
+	writeCache(versionsCache{Repo: "other", Fetched: time.Now(), Versions: []string{"1.0.0"}})
+	_, err = pkgConf.GetCachedVersions()
+	assert.True(err != nil) // Cache of another repo's recipe should be fetched again
+}
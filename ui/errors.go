@@ -0,0 +1,71 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorCode is a stable identifier for a kind of error, used in machine-readable output
+type ErrorCode string
+
+const (
+	CodeUnknown             ErrorCode = "unknown"
+	CodeInvalidArgs         ErrorCode = "invalid_args"
+	CodePromptUnavailable   ErrorCode = "prompt_unavailable"
+	CodeRecipeNotFound      ErrorCode = "recipe_not_found"
+	CodeUnsupportedPlatform ErrorCode = "unsupported_platform"
+	CodeVersionNotFound     ErrorCode = "version_not_found"
+	CodeDownloadFailed      ErrorCode = "download_failed"
+	CodeUnpackFailed        ErrorCode = "unpack_failed"
+	CodeLinkFailed          ErrorCode = "link_failed"
+	CodeInstallFailed       ErrorCode = "install_failed"
+	CodeNotInstalled        ErrorCode = "not_installed"
+	CodeRefreshFailed       ErrorCode = "refresh_failed"
+	CodeInvalidRecipes      ErrorCode = "invalid_recipes"
+	CodeGroupUnsatisfied    ErrorCode = "group_unsatisfied"
+	CodeUnhealthy           ErrorCode = "unhealthy"
+)
+
+// Error is an error with a stable ErrorCode
+type Error struct {
+	Code ErrorCode
+	Err  error
+}
+
+// Error implements error
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Errorf formats an error with the given ErrorCode
+func Errorf(code ErrorCode, format string, a ...any) error {
+	return &Error{Code: code, Err: fmt.Errorf(format, a...)}
+}
+
+// CodeOf returns the ErrorCode of an error, or CodeUnknown if it has none
+func CodeOf(err error) ErrorCode {
+	var codeErr *Error
+	if errors.As(err, &codeErr) {
+		return codeErr.Code
+	}
+	return CodeUnknown
+}
+
+// JSONError is the machine-readable form of an error
+type JSONError struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+}
+
+// NewJSONError converts an error to its machine-readable form
+func NewJSONError(err error) *JSONError {
+	if err == nil {
+		return nil
+	}
+	return &JSONError{Code: CodeOf(err), Message: err.Error()}
+}
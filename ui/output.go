@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fatih/color"
+)
+
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+var (
+	// OutputFormat is the format for command results, set by the global --output flag
+	OutputFormat = OutputText
+
+	jsonWriter  io.Writer = os.Stdout
+	jsonPrinted bool
+)
+
+// IsJSONOutput checks if command results should be printed as JSON
+func IsJSONOutput() bool {
+	return OutputFormat == OutputJSON
+}
+
+// SetOutputFormat validates and applies an output format.
+// In JSON mode, stdout is reserved for JSON documents, so all human-readable output
+// (including colored output and progress lines) is redirected to stderr without color.
+func SetOutputFormat(format string) error {
+	switch format {
+	case OutputText:
+	case OutputJSON:
+		jsonWriter = os.Stdout
+		os.Stdout = os.Stderr
+		color.Output = os.Stderr
+		color.NoColor = true
+	default:
+		return Errorf(CodeInvalidArgs, "unknown output format %q, expected %q or %q", format, OutputText, OutputJSON)
+	}
+	OutputFormat = format
+	return nil
+}
+
+// PrintJSON writes a value as a JSON document to stdout
+func PrintJSON(v any) error {
+	enc := json.NewEncoder(jsonWriter)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("unable to encode JSON output: %v", err)
+	}
+	jsonPrinted = true
+	return nil
+}
+
+// HasPrintedJSON checks if a command has already written its JSON document
+func HasPrintedJSON() bool {
+	return jsonPrinted
+}
@@ -16,3 +16,13 @@ func AreAnsiCodesEnabled() bool {
 	}
 	return isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd())
 }
+
+// Check if interactive prompts can be answered by checking the stdin file descriptor.
+// When stdin is not a terminal (scripts, pipes, Dockerfiles), prompting would hang or fail.
+// Prompts are also disabled for JSON output, which is meant for scripts.
+func AreInteractivePromptsEnabled() bool {
+	if IsJSONOutput() {
+		return false
+	}
+	return isatty.IsTerminal(os.Stdin.Fd()) || isatty.IsCygwinTerminal(os.Stdin.Fd())
+}
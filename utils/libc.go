@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"debug/elf"
+	"path/filepath"
+	"strings"
+)
+
+// Libcs are the C libraries that packages can have separate Linux builds for
+var Libcs = []string{"gnu", "musl"}
+
+// DetectLibc returns the C library of a Linux system, "gnu" or "musl", or "" for other operating systems.
+// It reads the dynamic loader the system shell was linked with, since both loaders can be installed.
+func DetectLibc(goos string) string {
+	if goos != "linux" {
+		return ""
+	}
+	if interp, err := elfInterpreter("/bin/sh"); err == nil && interp != "" {
+		if strings.Contains(interp, "musl") {
+			return "musl"
+		}
+		return "gnu"
+	}
+	if matches, _ := filepath.Glob("/lib/ld-musl-*.so.1"); len(matches) != 0 {
+		return "musl"
+	}
+	return "gnu"
+}
+
+// elfInterpreter returns the dynamic loader an executable requests, or "" if it is statically linked
+func elfInterpreter(path string) (string, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	for _, prog := range f.Progs {
+		if prog.Type != elf.PT_INTERP {
+			continue
+		}
+		data := make([]byte, prog.Filesz)
+		if _, err := prog.ReadAt(data, 0); err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(data), "\x00"), nil
+	}
+	return "", nil
+}
@@ -5,7 +5,10 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
+	"time"
+	"unicode"
 
 	"github.com/candrewlee14/webman/multiline"
 	"github.com/candrewlee14/webman/ui"
@@ -14,18 +17,29 @@ import (
 )
 
 var (
-	WebmanDir       string
-	WebmanConfig    string
-	WebmanPkgDir    string
-	WebmanBinDir    string
-	WebmanRecipeDir string
-	WebmanTmpDir    string
-	RecipeDirFlag   string
-	GOOS            string
-	GOARCH          string
-	PkgRecipeExt    = ".webman-pkg.yml"
-	GroupRecipeExt  = ".webman-group.yml"
-	UsingFileName   = "using.yaml"
+	WebmanDir           string
+	WebmanConfig        string
+	WebmanPkgDir        string
+	WebmanBinDir        string
+	WebmanRecipeDir     string
+	WebmanGroupDir      string
+	WebmanTmpDir        string
+	WebmanCacheDir      string
+	WebmanShareDir      string
+	RecipeDirFlag       string
+	RefreshFlag         bool
+	NoRefreshFlag       bool
+	AllowHooksFlag      bool
+	GOOS                string
+	GOARCH              string
+	LIBC                string
+	PkgRecipeExt        = ".webman-pkg.yml"
+	GroupRecipeExt      = ".webman-group.yml"
+	UsingFileName       = "using.yaml"
+	InstalledFileName   = "installed.yaml"
+	RecipeIndexFileName = "index.json"
+	RepoMetaExt         = ".meta.yaml"
+	ProjectFileName     = ".webman.yml"
 )
 
 func Init(homeDir string) {
@@ -34,9 +48,13 @@ func Init(homeDir string) {
 	WebmanPkgDir = filepath.Join(WebmanDir, "pkg")
 	WebmanBinDir = filepath.Join(WebmanDir, "bin")
 	WebmanRecipeDir = filepath.Join(WebmanDir, "recipes")
+	WebmanGroupDir = filepath.Join(WebmanDir, "groups")
 	WebmanTmpDir = filepath.Join(WebmanDir, "tmp")
+	WebmanCacheDir = filepath.Join(WebmanDir, "cache")
+	WebmanShareDir = filepath.Join(WebmanDir, "share")
 	GOOS = runtime.GOOS
 	GOARCH = runtime.GOARCH
+	LIBC = DetectLibc(GOOS)
 
 	if err := os.MkdirAll(WebmanBinDir, os.ModePerm); err != nil {
 		panic(err)
@@ -66,19 +84,25 @@ func Init(homeDir string) {
 	}
 }
 
-func ParsePkgVer(arg string) (string, string, error) {
+// ParsePkgVer parses a package argument in the format 'pkg', 'pkg@version', 'repo/pkg' or 'repo/pkg@version'
+func ParsePkgVer(arg string) (repo string, pkg string, ver string, err error) {
 	parts := strings.Split(arg, "@")
-	var pkg string
-	var ver string
-	if len(parts) == 1 {
-		pkg = parts[0]
-	} else if len(parts) == 2 {
-		pkg = parts[0]
+	if len(parts) > 2 {
+		return "", "", "", fmt.Errorf("packages should be in format 'pkg', 'pkg@version', or 'repo/pkg@version'")
+	}
+	pkg = parts[0]
+	if len(parts) == 2 {
 		ver = parts[1]
-	} else {
-		return "", "", fmt.Errorf("packages should be in format 'pkg' or 'pkg@version'")
 	}
-	return pkg, ver, nil
+	if repoParts := strings.Split(pkg, "/"); len(repoParts) == 2 {
+		repo, pkg = repoParts[0], repoParts[1]
+	} else if len(repoParts) > 2 {
+		return "", "", "", fmt.Errorf("packages should be in format 'pkg', 'pkg@version', or 'repo/pkg@version'")
+	}
+	if pkg == "" || (len(parts) == 2 && ver == "") || (strings.Contains(parts[0], "/") && repo == "") {
+		return "", "", "", fmt.Errorf("packages should be in format 'pkg', 'pkg@version', or 'repo/pkg@version'")
+	}
+	return repo, pkg, ver, nil
 }
 
 func CreateStem(pkg string, ver string) string {
@@ -102,3 +126,160 @@ func InstalledPackages() []string {
 	}
 	return pkgs
 }
+
+// InstalledPkgVerStems returns the installed version stems (pkg-ver) of a package, sorted from oldest to newest version
+func InstalledPkgVerStems(pkg string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(WebmanPkgDir, pkg))
+	if err != nil {
+		return nil, err
+	}
+	var stems []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			stems = append(stems, entry.Name())
+		}
+	}
+	sort.SliceStable(stems, func(i, j int) bool {
+		_, verI := ParseStem(stems[i])
+		_, verJ := ParseStem(stems[j])
+		return CompareVersions(verI, verJ) < 0
+	})
+	return stems, nil
+}
+
+// CompareVersions compares two version strings, treating runs of digits as numbers
+// so that 1.10.0 sorts after 1.9.0. It returns -1 if a < b, 0 if a == b, and 1 if a > b.
+func CompareVersions(a string, b string) int {
+	aParts := splitVersion(strings.TrimPrefix(a, "v"))
+	bParts := splitVersion(strings.TrimPrefix(b, "v"))
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		if c := compareVersionPart(aParts[i], bParts[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(aParts) < len(bParts):
+		return -1
+	case len(aParts) > len(bParts):
+		return 1
+	}
+	return 0
+}
+
+// IsVersionConstraint determines whether a version is a constraint with wildcard parts, like 1.22.x
+func IsVersionConstraint(ver string) bool {
+	for _, part := range strings.Split(ver, ".") {
+		if isWildcard(part) {
+			return true
+		}
+	}
+	return false
+}
+
+func isWildcard(part string) bool {
+	return part == "x" || part == "X" || part == "*"
+}
+
+// MatchVersion determines whether a version matches a constraint like 1.22.x, where an x or * part
+// matches any single part, or any remaining parts at the end of the constraint
+func MatchVersion(constraint string, ver string) bool {
+	cParts := strings.Split(strings.TrimPrefix(constraint, "v"), ".")
+	vParts := strings.Split(strings.TrimPrefix(ver, "v"), ".")
+	for i, cPart := range cParts {
+		if i >= len(vParts) {
+			return false
+		}
+		if isWildcard(cPart) {
+			if i == len(cParts)-1 {
+				return true
+			}
+			continue
+		}
+		if CompareVersions(cPart, vParts[i]) != 0 {
+			return false
+		}
+	}
+	return len(cParts) == len(vParts)
+}
+
+// SatisfiesVersion determines whether a version satisfies a requested version,
+// which may be empty for any version, an exact version, or a constraint like 1.22.x
+func SatisfiesVersion(requested string, ver string) bool {
+	switch {
+	case requested == "":
+		return true
+	case IsVersionConstraint(requested):
+		return MatchVersion(requested, ver)
+	default:
+		return CompareVersions(requested, ver) == 0
+	}
+}
+
+// splitVersion splits a version into alternating runs of digits and non-digits
+func splitVersion(ver string) []string {
+	var parts []string
+	start := 0
+	for i, ch := range ver {
+		if i > start && unicode.IsDigit(ch) != unicode.IsDigit(rune(ver[start])) {
+			parts = append(parts, ver[start:i])
+			start = i
+		}
+	}
+	if start < len(ver) {
+		parts = append(parts, ver[start:])
+	}
+	return parts
+}
+
+func compareVersionPart(a string, b string) int {
+	aNum := a != "" && unicode.IsDigit(rune(a[0]))
+	bNum := b != "" && unicode.IsDigit(rune(b[0]))
+	if aNum && bNum {
+		a = strings.TrimLeft(a, "0")
+		b = strings.TrimLeft(b, "0")
+		if len(a) != len(b) {
+			if len(a) < len(b) {
+				return -1
+			}
+			return 1
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+// DirSize returns the total size in bytes of all regular files under a path
+func DirSize(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// StaleTmpAge is how old a temporary file must be before it's considered left over,
+// so that the downloads of a running install or refresh aren't touched
+const StaleTmpAge = time.Hour
+
+// FormatBytes formats a byte count as a human-readable size, such as 12.3 MB
+func FormatBytes(size int64) string {
+	const unit = 1000
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "kMGTPE"[exp])
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestCompareVersions(t *testing.T) {
+	assert := is.New(t)
+
+	assert.Equal(CompareVersions("1.9.0", "1.10.0"), -1)  // 1.10.0 should be newer than 1.9.0
+	assert.Equal(CompareVersions("v1.2.3", "1.2.3"), 0)   // v prefix should be ignored
+	assert.Equal(CompareVersions("13.0.0", "12.1.1"), 1)  // 13.0.0 should be newer than 12.1.1
+	assert.Equal(CompareVersions("1.2", "1.2.1"), -1)     // shorter version should be older
+	assert.Equal(CompareVersions("0.10.0", "0.010.0"), 0) // leading zeros should be ignored
+}
+
+func TestMatchVersion(t *testing.T) {
+	assert := is.New(t)
+
+	assert.True(IsVersionConstraint("1.22.x"))       // x part should make a constraint
+	assert.True(!IsVersionConstraint("1.22.0"))      // exact version should not be a constraint
+	assert.True(MatchVersion("1.22.x", "1.22.5"))    // x should match any patch version
+	assert.True(MatchVersion("1.x", "1.22.5"))       // trailing x should match remaining parts
+	assert.True(MatchVersion("v1.*", "1.2"))         // v prefix should be ignored
+	assert.True(!MatchVersion("1.22.x", "1.21.5"))   // minor version should have to match
+	assert.True(!MatchVersion("1.22.x", "1.22"))     // x should need a part to match
+	assert.True(MatchVersion("1.x.3", "1.22.3"))     // inner x should match a single part
+	assert.True(SatisfiesVersion("", "1.0.0"))       // no requested version should accept any version
+	assert.True(SatisfiesVersion("v1.2.0", "1.2.0")) // exact version should ignore v prefix
+	assert.True(!SatisfiesVersion("1.2", "1.2.0"))   // exact version should not match a longer version
+}
+
+func TestInstalledPkgVerStems(t *testing.T) {
+	assert := is.New(t)
+
+	tmp := t.TempDir()
+	Init(tmp)
+	for _, ver := range []string{"1.10.0", "1.9.2", "1.21.0"} {
+		assert.NoErr(os.MkdirAll(filepath.Join(WebmanPkgDir, "go", CreateStem("go", ver)), os.ModePerm)) // Should create version dir
+	}
+	assert.NoErr(os.WriteFile(filepath.Join(WebmanPkgDir, "go", UsingFileName), nil, os.ModePerm)) // Should create using file
+
+	stems, err := InstalledPkgVerStems("go")
+	assert.NoErr(err)                                                   // Should list installed versions
+	assert.Equal(stems, []string{"go-1.9.2", "go-1.10.0", "go-1.21.0"}) // Should be sorted oldest to newest
+}
+
+func TestParsePkgVer(t *testing.T) {
+	assert := is.New(t)
+
+	repo, pkg, ver, err := ParsePkgVer("go")
+	assert.NoErr(err)                                              // Should parse a bare package
+	assert.Equal([]string{repo, pkg, ver}, []string{"", "go", ""}) // Should have no repo or version
+
+	repo, pkg, ver, err = ParsePkgVer("internal/go@1.22.1")
+	assert.NoErr(err)                                                            // Should parse a repo-qualified package
+	assert.Equal([]string{repo, pkg, ver}, []string{"internal", "go", "1.22.1"}) // Should split repo, package and version
+
+	for _, arg := range []string{"go@", "@1.0", "/go", "a/b/go", "go@1@2"} {
+		_, _, _, err = ParsePkgVer(arg)
+		assert.True(err != nil) // Should reject malformed package arguments
+	}
+}
+
+func TestDetectLibc(t *testing.T) {
+	assert := is.New(t)
+
+	assert.Equal(DetectLibc("darwin"), "") // Only Linux should have a C library variant
+	libc := DetectLibc("linux")
+	assert.True(libc == "gnu" || libc == "musl") // Linux should have a known C library
+}
+
+func TestDirSize(t *testing.T) {
+	assert := is.New(t)
+
+	tmp := t.TempDir()
+	assert.NoErr(os.MkdirAll(filepath.Join(tmp, "sub"), os.ModePerm))                         // Should create sub dir
+	assert.NoErr(os.WriteFile(filepath.Join(tmp, "a"), make([]byte, 100), os.ModePerm))       // Should create file
+	assert.NoErr(os.WriteFile(filepath.Join(tmp, "sub", "b"), make([]byte, 23), os.ModePerm)) // Should create nested file
+
+	size, err := DirSize(tmp)
+	assert.NoErr(err)              // Should walk dir
+	assert.Equal(size, int64(123)) // Should add up nested file sizes
+	size, err = DirSize(filepath.Join(tmp, "a"))
+	assert.NoErr(err)              // Should stat a single file
+	assert.Equal(size, int64(100)) // Should be the file size
+	_, err = DirSize(filepath.Join(tmp, "missing"))
+	assert.True(err != nil) // Missing path should be an error
+}
+
+func TestFormatBytes(t *testing.T) {
+	assert := is.New(t)
+
+	assert.Equal(FormatBytes(0), "0 B")                    // Zero should be in bytes
+	assert.Equal(FormatBytes(999), "999 B")                // Below a kilobyte should be in bytes
+	assert.Equal(FormatBytes(1000), "1.0 kB")              // A kilobyte should use the k prefix
+	assert.Equal(FormatBytes(12_345_678), "12.3 MB")       // Should round to one decimal
+	assert.Equal(FormatBytes(1_500_000_000_000), "1.5 TB") // Should scale to larger units
+}